@@ -0,0 +1,194 @@
+// Command hertzgen collects the describer comments of handlers and writes them
+// into a generated file, so server.Register does not need the Go source at runtime.
+//
+// Add the following directive to a package that registers handlers:
+//
+//	//go:generate go run github.com/maadiii/hertz/cmd/hertzgen
+//
+// and run `go generate ./...` before building.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const header = "// Code generated by hertzgen. DO NOT EDIT."
+
+var output = flag.String("o", "describers_gen.go", "name of the generated file in each package directory")
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("hertzgen: ")
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	pkgs, err := listPackages(patterns)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, pkg := range pkgs {
+		if err := generate(pkg); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+type goPackage struct {
+	Dir        string
+	ImportPath string
+	Name       string
+	GoFiles    []string
+}
+
+func listPackages(patterns []string) ([]goPackage, error) {
+	args := append([]string{"list", "-json"}, patterns...)
+
+	out, err := exec.Command("go", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+
+	var pkgs []goPackage
+
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var pkg goPackage
+		if err := dec.Decode(&pkg); err != nil {
+			return nil, err
+		}
+
+		pkgs = append(pkgs, pkg)
+	}
+
+	return pkgs, nil
+}
+
+func generate(pkg goPackage) error {
+	descriptions := make(map[string]string)
+	fset := token.NewFileSet()
+
+	for _, name := range pkg.GoFiles {
+		if name == *output {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil || fn.Type.TypeParams != nil {
+				continue
+			}
+
+			doc := fn.Doc.Text()
+			if !hasDescriber(doc) {
+				continue
+			}
+
+			descriptions[funcName(pkg, fn)] = doc
+		}
+	}
+
+	target := filepath.Join(pkg.Dir, *output)
+
+	if len(descriptions) == 0 {
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	src, err := render(pkg.Name, descriptions)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(target, src, 0o644) //nolint
+}
+
+func hasDescriber(doc string) bool {
+	for _, line := range strings.Split(doc, "\n") {
+		if strings.HasPrefix(line, "[") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// funcName returns the name runtime.FuncForPC reports for the function.
+func funcName(pkg goPackage, fn *ast.FuncDecl) string {
+	path := pkg.ImportPath
+	if pkg.Name == "main" {
+		path = "main"
+	}
+
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return path + "." + fn.Name.Name
+	}
+
+	switch recv := fn.Recv.List[0].Type.(type) {
+	case *ast.StarExpr:
+		return fmt.Sprintf("%s.(*%s).%s", path, typeName(recv.X), fn.Name.Name)
+	default:
+		return fmt.Sprintf("%s.%s.%s", path, typeName(recv), fn.Name.Name)
+	}
+}
+
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return typeName(t.X) + "[...]"
+	case *ast.IndexListExpr:
+		return typeName(t.X) + "[...]"
+	}
+
+	return ""
+}
+
+func render(pkgName string, descriptions map[string]string) ([]byte, error) {
+	names := make([]string, 0, len(descriptions))
+	for name := range descriptions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s\n\npackage %s\n\n", header, pkgName)
+	fmt.Fprintf(&buf, "import \"github.com/maadiii/hertz/server\"\n\n")
+	fmt.Fprintf(&buf, "func init() {\n\tserver.AddDescriptions(map[string]string{\n")
+
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t\t%q: %q,\n", name, descriptions[name])
+	}
+
+	fmt.Fprintf(&buf, "\t})\n}\n")
+
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+const source = `package handlers
+
+func Plain() {}
+func (*T) Pointer() {}
+func (T) Value() {}
+func (*G[K]) Generic() {}
+func (M[K, V]) Generics() {}
+`
+
+func TestFuncName(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "handlers.go", source, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decls := make(map[string]*ast.FuncDecl)
+	for _, decl := range file.Decls {
+		fn := decl.(*ast.FuncDecl)
+		decls[fn.Name.Name] = fn
+	}
+
+	pkg := goPackage{ImportPath: "example.com/handlers", Name: "handlers"}
+	mainPkg := goPackage{ImportPath: "example.com/cmd/api", Name: "main"}
+
+	// the runtime names of method values have a -fm suffix, which the server trims.
+	tests := []struct {
+		pkg         goPackage
+		fn          string
+		runtimeName string
+	}{
+		{pkg: pkg, fn: "Plain", runtimeName: "example.com/handlers.Plain"},
+		{pkg: pkg, fn: "Pointer", runtimeName: "example.com/handlers.(*T).Pointer"},
+		{pkg: pkg, fn: "Pointer", runtimeName: "example.com/handlers.(*T).Pointer-fm"},
+		{pkg: pkg, fn: "Value", runtimeName: "example.com/handlers.T.Value"},
+		{pkg: pkg, fn: "Value", runtimeName: "example.com/handlers.T.Value-fm"},
+		{pkg: pkg, fn: "Generic", runtimeName: "example.com/handlers.(*G[...]).Generic"},
+		{pkg: pkg, fn: "Generics", runtimeName: "example.com/handlers.M[...].Generics"},
+		{pkg: mainPkg, fn: "Plain", runtimeName: "main.Plain"},
+		{pkg: mainPkg, fn: "Pointer", runtimeName: "main.(*T).Pointer"},
+	}
+
+	for _, tt := range tests {
+		if got, want := funcName(tt.pkg, decls[tt.fn]), strings.TrimSuffix(tt.runtimeName, "-fm"); got != want {
+			t.Errorf("funcName(%s, %s) = %s, want %s", tt.pkg.Name, tt.fn, got, want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+
+	src, err := os.ReadFile(filepath.Join("testdata", "handlers", "handlers.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "handlers.go"), src, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg := goPackage{Dir: dir, ImportPath: "example.com/handlers", Name: "handlers", GoFiles: []string{"handlers.go"}}
+	if err := generate(pkg); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, *output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "describers_gen.go.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("generated:\n%s\nwant:\n%s", got, want)
+	}
+
+	// the generated file of a package without describers is removed.
+	if err := os.WriteFile(filepath.Join(dir, "handlers.go"), []byte("package handlers\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := generate(pkg); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, *output)); !os.IsNotExist(err) {
+		t.Errorf("the stale generated file is kept: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import "context"
+
+type Request struct{}
+
+type Users struct{}
+
+// GetUser returns a user.
+//
+// @authorize(admin)
+// [GET] /users/:id 200 json
+func GetUser(_ context.Context, _ *Request, _ *struct{}) (map[string]string, error) {
+	return nil, nil
+}
+
+// Create creates a user.
+//
+// [POST] /users 201 json
+func (*Users) Create(_ context.Context, _ *Request, _ *struct{}) (map[string]string, error) {
+	return nil, nil
+}
+
+// List lists the users.
+//
+// [GET] /users 200 json
+func (Users) List(_ context.Context, _ *Request, _ *struct{}) ([]string, error) {
+	return nil, nil
+}
+
+// helper has a doc comment without a describer.
+func helper() {}
+
+// Generic functions cannot be registered without their instantiation.
+//
+// [GET] /generic 200 json
+func Generic[T any](_ context.Context, _ *Request, _ *T) (T, error) {
+	var zero T
+
+	return zero, nil
+}
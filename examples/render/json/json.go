@@ -10,6 +10,8 @@ func init() {
 	server.Register(JSON)
 }
 
+// JSON returns the company of the given id.
+//
 // @authorize(role1, role2 ::: perm1, perm2)
 // @decorator
 // [GET] /api/v1/json/:id 200 json
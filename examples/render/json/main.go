@@ -3,74 +3,40 @@ package main
 import (
 	"context"
 	"log"
+	"strings"
 
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 	"github.com/maadiii/hertz/server"
+	"github.com/maadiii/hertz/server/openapi"
 )
 
+//go:generate go run github.com/maadiii/hertz/cmd/hertzgen
+
 func main() {
 	server.SetIdentifier(identify)
 	server.AddDecorator("decorator", decorator)
+	server.ServeOpenAPI(openapi.Info{Title: "json", Version: "1.0.0"}, "/docs")
 
 	hertz := server.Hertz(server.WithHostPorts(":8080"))
 	hertz.Spin()
 }
 
-func identify(_ context.Context, req *server.Request, roles []string, permissions ...string) {
+// identify only authenticates the request. The roles and permissions of the identity
+// are checked against the @authorize expression of the handler by the server.
+func identify(_ context.Context, req *server.Request, _ []string, _ ...string) {
 	if len(req.GetHeader("authorize")) == 0 {
 		req.AbortWithStatus(consts.StatusUnauthorized)
 
 		return
 	}
 
-	if len(roles) != 0 {
-		var matchRole bool
-
-		incomeRole := string(req.GetHeader("role"))
-		if len(incomeRole) == 0 {
-			req.AbortWithStatus(consts.StatusUnauthorized)
-
-			return
-		}
-
-		for _, r := range roles {
-			if incomeRole == r {
-				matchRole = true
-			}
-		}
-
-		if !matchRole {
-			req.AbortWithStatus(consts.StatusForbidden)
-
-			return
-		}
-	}
-
-	if len(permissions) != 0 {
-		var matchPermission bool
-
-		incomePerm := string(req.GetHeader("perm"))
-		if len(incomePerm) == 0 {
-			req.AbortWithStatus(consts.StatusForbidden)
-
-			return
-		}
-
-		for _, p := range permissions {
-			if incomePerm == p {
-				matchPermission = true
-			}
-		}
-
-		if !matchPermission {
-			req.AbortWithStatus(consts.StatusForbidden)
-
-			return
-		}
+	identity := server.Identity{
+		ID:   "1",
+		Role: string(req.GetHeader("role")),
 	}
 
-	identity := server.Identity{
-		ID: "1",
+	if perm := req.GetHeader("perm"); len(perm) != 0 {
+		identity.Permissions = strings.Split(string(perm), ",")
 	}
 
 	req.SetIdentity(identity)
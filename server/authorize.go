@@ -0,0 +1,311 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// authExpr is a parsed `@authorize` expression evaluated against the identity of a request.
+//
+//	@authorize(role:admin || (perm:orders.read && perm:orders.write))
+//	@authorize(!role:guest && perm:orders.*)
+//
+// A name ending with `.*` matches every name under its prefix, and `*` matches any name.
+// Wildcards only apply to the names of the expression, so an identity with the literal
+// orders.* permission does not have the orders.read permission.
+type authExpr interface {
+	eval(identity Identity) bool
+	String() string
+}
+
+type (
+	authOr    struct{ left, right authExpr }
+	authAnd   struct{ left, right authExpr }
+	authNot   struct{ expr authExpr }
+	authTerm  struct{ kind, name string }
+	authTrue  struct{}
+	authGroup struct{ expr authExpr }
+)
+
+func (e authOr) eval(identity Identity) bool {
+	return e.left.eval(identity) || e.right.eval(identity)
+}
+
+func (e authOr) String() string {
+	return e.left.String() + " || " + e.right.String()
+}
+
+func (e authAnd) eval(identity Identity) bool {
+	return e.left.eval(identity) && e.right.eval(identity)
+}
+
+func (e authAnd) String() string {
+	return e.left.String() + " && " + e.right.String()
+}
+
+func (e authNot) eval(identity Identity) bool {
+	return !e.expr.eval(identity)
+}
+
+func (e authNot) String() string {
+	return "!" + e.expr.String()
+}
+
+func (e authGroup) eval(identity Identity) bool {
+	return e.expr.eval(identity)
+}
+
+func (e authGroup) String() string {
+	return "(" + e.expr.String() + ")"
+}
+
+func (authTrue) eval(Identity) bool {
+	return true
+}
+
+func (authTrue) String() string {
+	return ""
+}
+
+func (e authTerm) eval(identity Identity) bool {
+	granted := identity.Permissions
+	if e.kind == "role" {
+		granted = append([]string{identity.Role}, identity.Roles...)
+	}
+
+	return slices.ContainsFunc(granted, func(name string) bool {
+		return name != "" && wildcardMatch(e.name, name)
+	})
+}
+
+func (e authTerm) String() string {
+	return e.kind + ":" + e.name
+}
+
+// wildcardMatch reports whether name matches pattern, where `*` matches any name
+// and a `prefix.*` pattern matches the names under prefix.
+func wildcardMatch(pattern, name string) bool {
+	if pattern == "*" || pattern == name {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(pattern, "*")
+
+	return ok && strings.HasSuffix(prefix, ".") && strings.HasPrefix(name, prefix)
+}
+
+// parseAuthorize parses the arguments of an `@authorize` directive. The former
+// `roles ::: permissions` lists are read as any of the roles and any of the permissions.
+func parseAuthorize(args string) (*identifierDescriber, error) {
+	args = strings.TrimSpace(args)
+
+	if args == "" {
+		return &identifierDescriber{Expr: authTrue{}}, nil
+	}
+
+	if isAuthorizeList(args) {
+		before, after, _ := strings.Cut(strings.ReplaceAll(args, " ", ""), ":::")
+
+		var roles, permissions []string
+		if len(before) > 0 {
+			roles = strings.Split(before, ",")
+		}
+
+		if len(after) > 0 {
+			permissions = strings.Split(after, ",")
+		}
+
+		return newIdentifierDescriber(roles, permissions), nil
+	}
+
+	p := &authParser{input: args}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.skipSpaces(); p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:])
+	}
+
+	return &identifierDescriber{Expr: expr}, nil
+}
+
+// isAuthorizeList reports whether args is in the former `r1,r2 ::: p1,p2` syntax.
+func isAuthorizeList(args string) bool {
+	if strings.Contains(args, ":::") {
+		return true
+	}
+
+	return !strings.ContainsAny(args, ":!&|()")
+}
+
+// listed reports whether the describer is of the former list syntax or has no
+// expression, so an identifier not setting the identity may authorize it by itself.
+func (d *identifierDescriber) listed() bool {
+	_, ok := d.Expr.(authTrue)
+
+	return ok || d.Roles != nil || d.Permissions != nil
+}
+
+// newIdentifierDescriber describes any of the roles and any of the permissions.
+func newIdentifierDescriber(roles, permissions []string) *identifierDescriber {
+	var expr authExpr = authTrue{}
+
+	if terms := authTerms("role", roles); terms != nil {
+		expr = terms
+	}
+
+	if terms := authTerms("perm", permissions); terms != nil {
+		if _, ok := expr.(authTrue); ok {
+			expr = terms
+		} else {
+			expr = authAnd{left: expr, right: terms}
+		}
+	}
+
+	return &identifierDescriber{Roles: roles, Permissions: permissions, Expr: expr}
+}
+
+func authTerms(kind string, names []string) authExpr {
+	var expr authExpr
+
+	for _, name := range names {
+		term := authTerm{kind: kind, name: name}
+		if expr == nil {
+			expr = term
+		} else {
+			expr = authOr{left: expr, right: term}
+		}
+	}
+
+	if len(names) > 1 {
+		return authGroup{expr: expr}
+	}
+
+	return expr
+}
+
+type authParser struct {
+	input string
+	pos   int
+}
+
+func (p *authParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid @authorize(%s) at %d: %s", p.input, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *authParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *authParser) consume(token string) bool {
+	p.skipSpaces()
+
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+
+		return true
+	}
+
+	return false
+}
+
+func (p *authParser) parseOr() (authExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = authOr{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *authParser) parseAnd() (authExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.consume("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = authAnd{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *authParser) parseUnary() (authExpr, error) {
+	if p.consume("!") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return authNot{expr: expr}, nil
+	}
+
+	if p.consume("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.consume(")") {
+			return nil, p.errorf("missing )")
+		}
+
+		return authGroup{expr: expr}, nil
+	}
+
+	return p.parseTerm()
+}
+
+func (p *authParser) parseTerm() (authExpr, error) {
+	p.skipSpaces()
+
+	start := p.pos
+	for p.pos < len(p.input) && !unicode.IsSpace(rune(p.input[p.pos])) && !strings.ContainsRune("()!&|", rune(p.input[p.pos])) {
+		p.pos++
+	}
+
+	if start == p.pos {
+		if p.pos == len(p.input) {
+			return nil, p.errorf("unexpected end")
+		}
+
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+
+	kind, name, _ := strings.Cut(p.input[start:p.pos], ":")
+	if kind != "role" && kind != "perm" {
+		p.pos = start
+
+		return nil, p.errorf("%q is not a role: or perm: term", p.input[start:])
+	}
+
+	if name == "" {
+		p.pos = start
+
+		return nil, p.errorf("%s: has no name", kind)
+	}
+
+	return authTerm{kind: kind, name: name}, nil
+}
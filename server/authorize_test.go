@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+func TestParseAuthorizePrecedence(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		// && binds tighter than ||, and ! tighter than &&.
+		{expr: "role:a || role:b && role:c", want: "role:a || (role:b && role:c)"},
+		{expr: "role:a && role:b || role:c", want: "(role:a && role:b) || role:c"},
+		{expr: "!role:a && role:b", want: "(!role:a) && role:b"},
+		{expr: "!(role:a || role:b)", want: "!(role:a || role:b)"},
+		{expr: "(role:a || role:b) && perm:c", want: "(role:a || role:b) && perm:c"},
+		{expr: "role:a||role:b&&!perm:c", want: "role:a || (role:b && (!perm:c))"},
+	}
+
+	for _, tt := range tests {
+		describer, err := parseAuthorize(tt.expr)
+		if err != nil {
+			t.Errorf("parseAuthorize(%q): %v", tt.expr, err)
+
+			continue
+		}
+
+		if got := grouped(describer.Expr); got != tt.want {
+			t.Errorf("parseAuthorize(%q) = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+// grouped prints an expression with every operation of more than a term grouped.
+func grouped(expr authExpr) string {
+	wrap := func(e authExpr) string {
+		switch e.(type) {
+		case authTerm, authGroup:
+			return grouped(e)
+		}
+
+		return "(" + grouped(e) + ")"
+	}
+
+	switch e := expr.(type) {
+	case authOr:
+		return wrap(e.left) + " || " + wrap(e.right)
+	case authAnd:
+		return wrap(e.left) + " && " + wrap(e.right)
+	case authNot:
+		return "!" + wrap(e.expr)
+	case authGroup:
+		return "(" + grouped(e.expr) + ")"
+	}
+
+	return expr.String()
+}
+
+func TestParseAuthorizeErrors(t *testing.T) {
+	tests := map[string]string{
+		"role:a &&":          "unexpected end",
+		"role:a || ":         "unexpected end",
+		"(role:a || role:b":  "missing )",
+		"role:a)":            `unexpected ")"`,
+		"role: && perm:b":    "role: has no name",
+		"user:a || role:b":   "is not a role: or perm: term",
+		"role:a role:b":      `unexpected "role:b"`,
+		"&& role:a":          `unexpected '&'`,
+		"!":                  "unexpected end",
+		"role:a & role:b":    `unexpected "& role:b"`,
+		"role:a ||| perm:b":  `unexpected '|'`,
+		"role:a && (perm:b)": "",
+	}
+
+	for expr, want := range tests {
+		_, err := parseAuthorize(expr)
+
+		switch {
+		case want == "" && err != nil:
+			t.Errorf("parseAuthorize(%q): %v", expr, err)
+		case want != "" && err == nil:
+			t.Errorf("parseAuthorize(%q) is valid, want %s", expr, want)
+		case want != "" && !strings.Contains(err.Error(), want):
+			t.Errorf("parseAuthorize(%q) = %v, want %s", expr, err, want)
+		}
+	}
+}
+
+func TestAuthorizeEval(t *testing.T) {
+	admin := Identity{Role: "admin", Permissions: []string{"orders.read"}}
+	ops := Identity{Role: "user", Roles: []string{"ops"}, Permissions: []string{"orders.read", "orders.write"}}
+	wildcard := Identity{Role: "*", Permissions: []string{"orders.*", "*"}}
+	guest := Identity{Role: "guest"}
+
+	tests := []struct {
+		expr     string
+		identity Identity
+		want     bool
+	}{
+		{expr: "role:admin", identity: admin, want: true},
+		{expr: "role:ops", identity: ops, want: true},
+		{expr: "role:admin || perm:orders.write", identity: ops, want: true},
+		{expr: "role:admin && perm:orders.write", identity: admin, want: false},
+		{expr: "!role:guest && perm:orders.read", identity: admin, want: true},
+		{expr: "!role:guest", identity: guest, want: false},
+		{expr: "role:guest || role:admin && perm:orders.write", identity: guest, want: true},
+		{expr: "(role:guest || role:admin) && perm:orders.write", identity: guest, want: false},
+		// wildcards of the route match the names of the identity.
+		{expr: "perm:orders.*", identity: ops, want: true},
+		{expr: "perm:invoices.*", identity: ops, want: false},
+		{expr: "perm:*", identity: admin, want: true},
+		{expr: "perm:*", identity: guest, want: false},
+		{expr: "perm:orders.*", identity: Identity{Permissions: []string{"ordersX"}}, want: false},
+		{expr: "perm:orders*", identity: Identity{Permissions: []string{"orders.read"}}, want: false},
+		// the wildcards of the identity are literal names.
+		{expr: "perm:orders.read", identity: wildcard, want: false},
+		{expr: "role:admin", identity: wildcard, want: false},
+		{expr: "perm:orders.*", identity: wildcard, want: true},
+		// the former lists are any of the roles and any of the permissions.
+		{expr: "admin, ops ::: orders.write", identity: ops, want: true},
+		{expr: "admin, ops ::: orders.write", identity: admin, want: false},
+		{expr: "::: orders.read", identity: admin, want: true},
+		{expr: "admin", identity: ops, want: false},
+		{expr: "", identity: guest, want: true},
+	}
+
+	for _, tt := range tests {
+		describer, err := parseAuthorize(tt.expr)
+		if err != nil {
+			t.Errorf("parseAuthorize(%q): %v", tt.expr, err)
+
+			continue
+		}
+
+		if got := describer.Expr.eval(tt.identity); got != tt.want {
+			t.Errorf("%q of %+v = %t, want %t", tt.expr, tt.identity, got, tt.want)
+		}
+	}
+}
+
+// authorizedExpr requires an expression.
+//
+// @authorize(role:admin || perm:orders.read)
+// [GET] /expr 200 json
+func authorizedExpr(_ context.Context, _ *Request, _ *struct{}) (map[string]bool, error) {
+	return map[string]bool{"ok": true}, nil
+}
+
+// authorizedList requires the former lists.
+//
+// @authorize(admin ::: orders.read)
+// [GET] /list 200 json
+func authorizedList(_ context.Context, _ *Request, _ *struct{}) (map[string]bool, error) {
+	return map[string]bool{"ok": true}, nil
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier identifierFn
+		path       string
+		status     int
+	}{
+		{
+			name: "identity of the expression",
+			identifier: func(_ context.Context, req *Request, _ []string, _ ...string) {
+				req.SetIdentity(Identity{ID: "1", Permissions: []string{"orders.read"}})
+			},
+			path:   "/expr",
+			status: http.StatusOK,
+		},
+		{
+			name: "identity out of the expression",
+			identifier: func(_ context.Context, req *Request, _ []string, _ ...string) {
+				req.SetIdentity(Identity{ID: "1", Role: "guest"})
+			},
+			path:   "/expr",
+			status: http.StatusForbidden,
+		},
+		{
+			name:       "no identity for an expression",
+			identifier: func(context.Context, *Request, []string, ...string) {},
+			path:       "/expr",
+			status:     http.StatusUnauthorized,
+		},
+		{
+			name: "identity out of the lists",
+			identifier: func(_ context.Context, req *Request, _ []string, _ ...string) {
+				req.SetIdentity(Identity{ID: "1", Role: "admin"})
+			},
+			path:   "/list",
+			status: http.StatusForbidden,
+		},
+		{
+			name: "lists authorized by the identifier",
+			identifier: func(_ context.Context, _ *Request, roles []string, permissions ...string) {
+				if len(roles) != 1 || roles[0] != "admin" || len(permissions) != 1 || permissions[0] != "orders.read" {
+					panic("the identifier did not get the lists")
+				}
+			},
+			path:   "/list",
+			status: http.StatusOK,
+		},
+		{
+			name: "lists rejected by the identifier",
+			identifier: func(c context.Context, req *Request, _ []string, _ ...string) {
+				req.Fail(c, Forbidden("not an admin"))
+			},
+			path:   "/list",
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		s := New()
+		s.SetIdentifier(tt.identifier)
+		s.Register(NewAction(authorizedExpr), NewAction(authorizedList))
+
+		res := ut.PerformRequest(s.Build().Engine, http.MethodGet, tt.path, nil)
+		if status := res.Code; status != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, status, tt.status, res.Body.String())
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package server
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// BindError reports the fields of a request which cannot be bound to the input of a handler.
+// It is passed to the ErrorHandler of the server, and AsHTTPError renders it as 422 with
+// an error per field.
+type BindError struct {
+	Fields []FieldError
+
+	err error
+}
+
+func (e *BindError) Error() string {
+	if len(e.Fields) == 0 {
+		return "request binding failed: " + e.err.Error()
+	}
+
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+" "+f.Message)
+	}
+
+	return "request binding failed: " + strings.Join(msgs, ", ")
+}
+
+// Unwrap returns the error of the binder.
+func (e *BindError) Unwrap() error {
+	return e.err
+}
+
+// bindSources are the struct tags of the request parts in the order the binder reads them.
+var bindSources = []string{"path", "query", "header", "cookie", "form"}
+
+// newBindError finds the fields of the in struct the request cannot be bound to.
+// The binder stops at the first failing field and only reports it as text, so
+// the raw values of the request are checked against the field types again.
+func newBindError(rctx *app.RequestContext, in reflect.Type, err error) *BindError {
+	bindErr := &BindError{err: err}
+
+	for in.Kind() == reflect.Pointer {
+		in = in.Elem()
+	}
+
+	if in.Kind() != reflect.Struct {
+		return bindErr
+	}
+
+	bindErr.Fields = append(bindErr.Fields, paramFieldErrors(rctx, in)...)
+	bindErr.Fields = append(bindErr.Fields, bodyFieldErrors(rctx, in)...)
+
+	return bindErr
+}
+
+func paramFieldErrors(rctx *app.RequestContext, in reflect.Type) (fields []FieldError) {
+	for i := 0; i < in.NumField(); i++ {
+		field := in.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && indirect(field.Type).Kind() == reflect.Struct {
+			fields = append(fields, paramFieldErrors(rctx, indirect(field.Type))...)
+
+			continue
+		}
+
+		for _, source := range bindSources {
+			tag, ok := field.Tag.Lookup(source)
+			if !ok {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(tag, ",")
+			if name == "-" {
+				continue
+			}
+
+			if name == "" {
+				name = field.Name
+			}
+
+			values := paramValues(rctx, source, name)
+			if len(values) == 0 {
+				if strings.Contains(opts, "required") {
+					fields = append(fields, FieldError{
+						Field: name, Source: source, Expected: expectedType(field.Type), Message: "is required",
+					})
+				}
+
+				continue
+			}
+
+			for _, value := range values {
+				if fe, ok := checkValue(field.Type, value); !ok {
+					fe.Field, fe.Source = name, source
+					fields = append(fields, fe)
+
+					break
+				}
+			}
+		}
+	}
+
+	return fields
+}
+
+func paramValues(rctx *app.RequestContext, source, name string) []string {
+	var values [][]byte
+
+	switch source {
+	case "path":
+		if value, ok := rctx.Params.Get(name); ok {
+			return []string{value}
+		}
+	case "query":
+		values = rctx.QueryArgs().PeekAll(name)
+	case "header":
+		values = rctx.Request.Header.PeekAll(name)
+	case "cookie":
+		if value := rctx.Cookie(name); value != nil {
+			values = [][]byte{value}
+		}
+	case "form":
+		values = rctx.PostArgs().PeekAll(name)
+		if form, err := rctx.MultipartForm(); err == nil {
+			for _, value := range form.Value[name] {
+				values = append(values, []byte(value))
+			}
+		}
+
+		if len(values) == 0 {
+			values = rctx.QueryArgs().PeekAll(name)
+		}
+	}
+
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		strs = append(strs, string(value))
+	}
+
+	return strs
+}
+
+// checkValue reports whether the raw value can be decoded as the type t, or the
+// elements of t if it is a slice.
+func checkValue(t reflect.Type, value string) (FieldError, bool) {
+	t = indirect(t)
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		t = indirect(t.Elem())
+	}
+
+	fe := FieldError{Value: value, Expected: expectedType(t)}
+
+	var err error
+
+	if reflect.PointerTo(t).Implements(textUnmarshaler) {
+		err = reflect.New(t).Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	} else {
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			_, err = strconv.ParseInt(value, 10, t.Bits())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			_, err = strconv.ParseUint(value, 10, t.Bits())
+		case reflect.Float32, reflect.Float64:
+			_, err = strconv.ParseFloat(value, t.Bits())
+		case reflect.Bool:
+			_, err = strconv.ParseBool(value)
+		}
+	}
+
+	if err == nil {
+		return fe, true
+	}
+
+	fe.Message = "must be " + article(fe.Expected)
+
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) && errors.Is(numErr.Err, strconv.ErrRange) {
+		fe.Message = "is out of the range of " + t.Kind().String()
+	}
+
+	return fe, false
+}
+
+// bodyFieldErrors decodes a JSON body again to report the field of a type mismatch.
+func bodyFieldErrors(rctx *app.RequestContext, in reflect.Type) []FieldError {
+	body := rctx.Request.Body()
+	if len(body) == 0 || mediaType(string(rctx.Request.Header.ContentType())) != "application/json" {
+		return nil
+	}
+
+	err := json.Unmarshal(body, reflect.New(in).Interface())
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		expected := expectedType(typeErr.Type)
+
+		return []FieldError{{
+			Field: typeErr.Field, Source: "body", Value: typeErr.Value, Expected: expected,
+			Message: "must be " + article(expected),
+		}}
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return []FieldError{{
+			Source: "body", Expected: "json", Message: "is not valid JSON at offset " + strconv.FormatInt(syntaxErr.Offset, 10),
+		}}
+	}
+
+	return nil
+}
+
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// expectedType names the type t for clients.
+func expectedType(t reflect.Type) string {
+	t = indirect(t)
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		if t.Name() == "" || t.Kind() == reflect.Map {
+			return "object"
+		}
+	}
+
+	return t.String()
+}
+
+func article(noun string) string {
+	if strings.ContainsAny(noun[:1], "aeiou") {
+		return "an " + noun
+	}
+
+	return "a " + noun
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+type listItemsRequest struct {
+	ShopID int    `path:"shop"`
+	Page   int    `query:"page,required"`
+	Limit  uint8  `header:"X-Limit"`
+	Tags   []int  `query:"tag"`
+	Sort   string `query:"sort"`
+}
+
+// listItems lists the items of a shop.
+//
+// [GET] /shops/:shop/items 200 json
+func listItems(_ context.Context, _ *Request, in *listItemsRequest) (*listItemsRequest, error) {
+	return in, nil
+}
+
+type renameItemRequest struct {
+	Name  string `json:"name"`
+	Stock int    `json:"stock"`
+}
+
+// renameItem renames an item.
+//
+// [PUT] /items 200 json
+func renameItem(_ context.Context, _ *Request, in *renameItemRequest) (*renameItemRequest, error) {
+	return in, nil
+}
+
+func TestBindError(t *testing.T) {
+	s := New()
+
+	var handled error
+
+	s.SetErrorHandler(func(c context.Context, rctx *app.RequestContext, err error) {
+		handled = err
+		ProblemErrorHandler(c, rctx, err)
+	})
+	s.Register(NewAction(listItems), NewAction(renameItem))
+
+	engine := s.Build().Engine
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		header ut.Header
+		fields []FieldError
+	}{
+		{
+			name: "params", method: http.MethodGet, path: "/shops/one/items?page=2&tag=1&tag=x",
+			header: ut.Header{Key: "X-Limit", Value: "300"},
+			fields: []FieldError{
+				{Field: "shop", Source: "path", Value: "one", Expected: "integer", Message: "must be an integer"},
+				{Field: "X-Limit", Source: "header", Value: "300", Expected: "integer", Message: "is out of the range of uint8"},
+				{Field: "tag", Source: "query", Value: "x", Expected: "integer", Message: "must be an integer"},
+			},
+		},
+		{
+			name: "required", method: http.MethodGet, path: "/shops/1/items",
+			fields: []FieldError{
+				{Field: "page", Source: "query", Expected: "integer", Message: "is required"},
+			},
+		},
+		{
+			name: "body", method: http.MethodPut, path: "/items", body: `{"name":"pen","stock":"many"}`,
+			header: ut.Header{Key: "Content-Type", Value: "application/json"},
+			fields: []FieldError{
+				{Field: "stock", Source: "body", Value: "string", Expected: "integer", Message: "must be an integer"},
+			},
+		},
+		{
+			name: "syntax", method: http.MethodPut, path: "/items", body: `{"name":`,
+			header: ut.Header{Key: "Content-Type", Value: "application/json"},
+			fields: []FieldError{
+				{Source: "body", Expected: "json", Message: "is not valid JSON at offset 8"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		handled = nil
+
+		var body *ut.Body
+		if tt.body != "" {
+			body = &ut.Body{Body: strings.NewReader(tt.body), Len: len(tt.body)}
+		}
+
+		res := ut.PerformRequest(engine, tt.method, tt.path, body, tt.header)
+		if res.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status = %d %s, want 422", tt.name, res.Code, res.Body.String())
+
+			continue
+		}
+
+		var bindErr *BindError
+		if !errors.As(handled, &bindErr) {
+			t.Errorf("%s: ErrorHandler called with %v, want a *BindError", tt.name, handled)
+
+			continue
+		}
+
+		if !reflect.DeepEqual(bindErr.Fields, tt.fields) {
+			t.Errorf("%s: fields = %+v, want %+v", tt.name, bindErr.Fields, tt.fields)
+		}
+
+		var problem HTTPError
+		if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil || fmt.Sprint(problem.Fields) != fmt.Sprint(tt.fields) {
+			t.Errorf("%s: problem = %s, want the fields", tt.name, res.Body.String())
+		}
+	}
+}
+
+func TestCheckValue(t *testing.T) {
+	tests := []struct {
+		t       reflect.Type
+		value   string
+		ok      bool
+		message string
+	}{
+		{reflect.TypeOf(0), "12", true, ""},
+		{reflect.TypeOf(0), "1.5", false, "must be an integer"},
+		{reflect.TypeOf(int8(0)), "200", false, "is out of the range of int8"},
+		{reflect.TypeOf(uint(0)), "-1", false, "must be an integer"},
+		{reflect.TypeOf(0.0), "1e3", true, ""},
+		{reflect.TypeOf(false), "yes", false, "must be a boolean"},
+		{reflect.TypeOf(new(int)), "3", true, ""},
+		{reflect.TypeOf([]int{}), "x", false, "must be an integer"},
+		{reflect.TypeOf(""), "anything", true, ""},
+		{reflect.TypeOf(time.Time{}), "2024-01-02T03:04:05Z", true, ""},
+		{reflect.TypeOf(time.Time{}), "yesterday", false, "must be a time.Time"},
+	}
+
+	for _, tt := range tests {
+		fe, ok := checkValue(tt.t, tt.value)
+		if ok != tt.ok || fe.Message != tt.message {
+			t.Errorf("checkValue(%v, %q) = %+v, %v", tt.t, tt.value, fe, ok)
+		}
+	}
+}
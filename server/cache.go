@@ -0,0 +1,485 @@
+package server
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// CacheEntry is a cached response.
+type CacheEntry struct {
+	Status       int
+	Header       [][2]string
+	Body         []byte
+	ETag         string
+	LastModified time.Time
+	// Tags are the tags of the @cache directive and the tag of the route, which invalidate the entry.
+	Tags []string
+}
+
+// CacheBackend stores the responses of the `@cache` decorator.
+type CacheBackend interface {
+	// Get returns the entry of key, or nil if it is not cached.
+	Get(c context.Context, key string) (*CacheEntry, error)
+	// Set caches the entry of key for ttl.
+	Set(c context.Context, key string, entry *CacheEntry, ttl time.Duration) error
+	// Invalidate removes the entries having any of the tags.
+	Invalidate(c context.Context, tags ...string) error
+}
+
+// defaultCacheCapacity is the capacity of the default in-memory backend.
+const defaultCacheCapacity = 1024
+
+// SetCacheBackend sets the backend of the `@cache` decorator, an in-memory LRU of
+// 1024 responses by default.
+//
+//	// [GET] /products/:id 200 json
+//	// @cache(30s, vary=Accept-Language, tag=products)
+//	func GetProduct(c context.Context, req *server.Request, in *GetProductRequest) (*Product, error)
+//
+// Only 200 responses of GET and HEAD requests without cookies are cached. The responses
+// of authenticated requests are cached per identity and are private, and those of the
+// requests an identifier authorizes without setting an identity are not cached. The routes with
+// a `@policy` cannot be cached, since a cached response would skip the policy. The cached
+// responses have a strong ETag and a Last-Modified header, and the If-None-Match and
+// If-Modified-Since requests are answered with 304. The `vary` headers, which can be
+// repeated, are part of the cache key, and so is Accept for the negotiate responder.
+// The sse and websocket routes cannot be cached, since their responses are streamed.
+func (s *Server) SetCacheBackend(backend CacheBackend) {
+	s.cache = backend
+}
+
+// SetCacheBackend sets the backend of the `@cache` decorator.
+func SetCacheBackend(backend CacheBackend) {
+	defaultServer.SetCacheBackend(backend)
+}
+
+// InvalidateCache removes the cached responses having any of the tags of `@cache(ttl, tag=name)`.
+func (s *Server) InvalidateCache(c context.Context, tags ...string) error {
+	return s.cache.Invalidate(c, tags...)
+}
+
+// InvalidateCache removes the cached responses having any of the tags of `@cache(ttl, tag=name)`.
+func InvalidateCache(c context.Context, tags ...string) error {
+	return defaultServer.InvalidateCache(c, tags...)
+}
+
+// InvalidateRoute removes the cached responses of a route, such as `GET /products/:id`.
+// The responses of HEAD requests are removed with the GET ones.
+func (s *Server) InvalidateRoute(c context.Context, verb, path string) error {
+	tags := []string{routeCacheTag(verb, path)}
+	if strings.EqualFold(verb, http.MethodGet) {
+		tags = append(tags, routeCacheTag(http.MethodHead, path))
+	}
+
+	return s.cache.Invalidate(c, tags...)
+}
+
+// InvalidateRoute removes the cached responses of a route, such as `GET /products/:id`.
+func InvalidateRoute(c context.Context, verb, path string) error {
+	return defaultServer.InvalidateRoute(c, verb, path)
+}
+
+// InvalidateCache removes the cached responses having any of the tags, from the
+// handlers changing them.
+func (req *Request) InvalidateCache(c context.Context, tags ...string) error {
+	if s := serverOf(req.rc); s != nil {
+		return s.InvalidateCache(c, tags...)
+	}
+
+	return nil
+}
+
+// InvalidateRoute removes the cached responses of a route, from the handlers changing them.
+func (req *Request) InvalidateRoute(c context.Context, verb, path string) error {
+	if s := serverOf(req.rc); s != nil {
+		return s.InvalidateRoute(c, verb, path)
+	}
+
+	return nil
+}
+
+func routeCacheTag(verb, path string) string {
+	return "route:" + strings.ToUpper(verb) + " " + path
+}
+
+type cacheDirective struct {
+	ttl  time.Duration
+	vary []string
+	tags []string
+}
+
+// parseCacheDirective parses the `ttl, vary=Header, tag=name` arguments of `@cache`.
+func parseCacheDirective(args []string) (*cacheDirective, error) {
+	if len(args) == 0 {
+		return nil, errors.New("the ttl is missing, as @cache(30s)")
+	}
+
+	ttl, err := time.ParseDuration(args[0])
+	if err != nil || ttl <= 0 {
+		return nil, fmt.Errorf("invalid ttl %q", args[0])
+	}
+
+	directive := &cacheDirective{ttl: ttl}
+
+	for _, arg := range args[1:] {
+		name, value, ok := strings.Cut(arg, "=")
+		if value = strings.TrimSpace(value); !ok || value == "" {
+			return nil, fmt.Errorf("invalid argument %q, expected name=value", arg)
+		}
+
+		switch strings.TrimSpace(name) {
+		case "vary":
+			directive.vary = append(directive.vary, http.CanonicalHeaderKey(value))
+		case "tag":
+			directive.tags = append(directive.tags, value)
+		default:
+			return nil, fmt.Errorf("unknown argument %q", name)
+		}
+	}
+
+	return directive, nil
+}
+
+// cacheDecorator is the factory of the `@cache` decorator.
+func (s *Server) cacheDecorator(args []string) (decoratorFn, error) {
+	directive, err := parseCacheDirective(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c context.Context, req *Request) {
+		method := req.Method()
+		if method != http.MethodGet && method != http.MethodHead {
+			return
+		}
+
+		// the response of an authorized request without an identity cannot be scoped to its caller.
+		if req.rc.GetBool(anonymousKey) {
+			return
+		}
+
+		key := directive.key(req)
+
+		entry, err := s.cache.Get(c, key)
+		if err != nil {
+			_ = req.Error(err)
+		}
+
+		if entry != nil {
+			req.Abort()
+			directive.replay(req, entry)
+
+			return
+		}
+
+		req.Next(c)
+
+		res := &req.rc.Response
+		if res.StatusCode() != http.StatusOK || res.IsBodyStream() || req.rc.IsAborted() || !cacheable(res) {
+			return
+		}
+
+		entry = newCacheEntry(res, append([]string{routeCacheTag(method, req.FullPath())}, directive.tags...))
+
+		if err := s.cache.Set(c, key, entry, directive.ttl); err != nil {
+			_ = req.Error(err)
+		}
+
+		directive.setHeaders(req, entry)
+
+		if notModified(req, entry) {
+			res.SetStatusCode(http.StatusNotModified)
+			res.ResetBody()
+		}
+	}, nil
+}
+
+// key is the cache key of the request, the method, the URI, the identity of an
+// authenticated request and the vary headers.
+func (d *cacheDirective) key(req *Request) string {
+	var b strings.Builder
+
+	b.WriteString("cache:")
+	b.WriteString(req.Method())
+	b.WriteString(" ")
+	b.Write(req.URI().RequestURI())
+
+	if identity, ok := req.LookupIdentity(); ok {
+		b.WriteString("\nidentity: ")
+		b.WriteString(identity.ID)
+	}
+
+	for _, header := range d.vary {
+		b.WriteString("\n")
+		b.WriteString(header)
+		b.WriteString(": ")
+		b.Write(req.GetHeader(header))
+	}
+
+	return b.String()
+}
+
+// replay writes a cached response. The headers the previous handlers set, such as
+// the RateLimit headers, are kept.
+func (d *cacheDirective) replay(req *Request, entry *CacheEntry) {
+	res := &req.rc.Response
+
+	restoreHeaders(res, entry.Header)
+	d.setHeaders(req, entry)
+	res.Header.Set("Age", strconv.Itoa(int(time.Since(entry.LastModified).Seconds())))
+
+	if notModified(req, entry) {
+		res.SetStatusCode(http.StatusNotModified)
+
+		return
+	}
+
+	res.SetStatusCode(entry.Status)
+	res.SetBody(entry.Body)
+}
+
+// setHeaders sets the validators and the Cache-Control of a cached response, which
+// is private to the identity of an authenticated request.
+func (d *cacheDirective) setHeaders(req *Request, entry *CacheEntry) {
+	res := &req.rc.Response
+
+	res.Header.Set("ETag", entry.ETag)
+	res.Header.Set("Last-Modified", entry.LastModified.UTC().Format(http.TimeFormat))
+
+	if len(d.vary) > 0 {
+		var vary []string
+		if existing := res.Header.Peek("Vary"); len(existing) > 0 {
+			vary = append(vary, string(existing))
+		}
+
+		// the headers the responder varies by already, such as Accept, are not repeated.
+		for _, header := range d.vary {
+			if !slices.ContainsFunc(vary, func(v string) bool { return varies(v, header) }) {
+				vary = append(vary, header)
+			}
+		}
+
+		res.Header.Set("Vary", strings.Join(vary, ", "))
+	}
+
+	if len(res.Header.Peek("Cache-Control")) == 0 {
+		control := "max-age=" + strconv.Itoa(int(d.ttl.Seconds()))
+		_, authenticated := req.LookupIdentity()
+		if authenticated || slices.Contains(d.vary, "Authorization") || slices.Contains(d.vary, "Cookie") {
+			control = "private, " + control
+		}
+
+		res.Header.Set("Cache-Control", control)
+	}
+}
+
+// varies reports whether the Vary value lists header.
+func varies(vary, header string) bool {
+	for _, name := range strings.Split(vary, ",") {
+		if strings.EqualFold(strings.TrimSpace(name), header) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cacheable reports whether the response may be shared, it sets no cookies and
+// its Cache-Control does not forbid storing it.
+func cacheable(res *protocol.Response) bool {
+	cookies := false
+	res.Header.VisitAllCookie(func(_, _ []byte) { cookies = true })
+
+	control := bytes.ToLower(res.Header.Peek("Cache-Control"))
+
+	return !cookies && !bytes.Contains(control, []byte("no-store"))
+}
+
+// uncachedHeaders are the response headers which are not stored to be replayed.
+var uncachedHeaders = []string{
+	"Age", "Connection", "Content-Length", "Date", "ETag", "Last-Modified",
+	"Retry-After", "Server", "Set-Cookie", "Trailer", "Transfer-Encoding",
+}
+
+// storedHeaders returns the response headers to replay, without the headers of the
+// connection, the cookies and the RateLimit headers of the request.
+func storedHeaders(res *protocol.Response) (headers [][2]string) {
+	res.Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+		if slices.ContainsFunc(uncachedHeaders, func(h string) bool { return strings.EqualFold(h, name) }) ||
+			strings.HasPrefix(strings.ToLower(name), "ratelimit-") {
+			return
+		}
+
+		headers = append(headers, [2]string{name, string(value)})
+	})
+
+	return headers
+}
+
+// restoreHeaders sets the stored headers which the previous handlers did not set,
+// and the content type.
+func restoreHeaders(res *protocol.Response, headers [][2]string) {
+	set := make(map[string]bool)
+	res.Header.VisitAll(func(key, _ []byte) {
+		set[strings.ToLower(string(key))] = true
+	})
+
+	for _, kv := range headers {
+		if strings.EqualFold(kv[0], "Content-Type") {
+			res.Header.SetContentType(kv[1])
+		} else if !set[strings.ToLower(kv[0])] {
+			res.Header.Add(kv[0], kv[1])
+		}
+	}
+}
+
+func newCacheEntry(res *protocol.Response, tags []string) *CacheEntry {
+	entry := &CacheEntry{
+		Status:       res.StatusCode(),
+		Header:       storedHeaders(res),
+		Body:         bytes.Clone(res.Body()),
+		LastModified: time.Now().Truncate(time.Second),
+		Tags:         tags,
+	}
+
+	sum := sha256.Sum256(entry.Body)
+	entry.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	return entry
+}
+
+// notModified reports whether the conditional request has the cached representation.
+// If-None-Match takes precedence over If-Modified-Since.
+func notModified(req *Request, entry *CacheEntry) bool {
+	if match := req.GetHeader("If-None-Match"); len(match) > 0 {
+		for _, tag := range strings.Split(string(match), ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == entry.ETag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return len(req.GetHeader("If-Modified-Since")) > 0 && !req.IfModifiedSince(entry.LastModified)
+}
+
+// LRUCache is an in-memory CacheBackend evicting the least recently used entries.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	tags     map[string]map[string]struct{}
+}
+
+type lruItem struct {
+	key     string
+	entry   *CacheEntry
+	expires time.Time
+}
+
+// NewLRUCache creates an LRUCache holding up to capacity entries.
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity <= 0 {
+		capacity = defaultCacheCapacity
+	}
+
+	return &LRUCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		tags:     make(map[string]map[string]struct{}),
+	}
+}
+
+func (l *LRUCache) Get(_ context.Context, key string) (*CacheEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[key]
+	if !ok {
+		return nil, nil
+	}
+
+	item := elem.Value.(*lruItem)
+	if !time.Now().Before(item.expires) {
+		l.remove(elem)
+
+		return nil, nil
+	}
+
+	l.order.MoveToFront(elem)
+
+	return item.entry, nil
+}
+
+func (l *LRUCache) Set(_ context.Context, key string, entry *CacheEntry, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[key]; ok {
+		l.remove(elem)
+	}
+
+	l.entries[key] = l.order.PushFront(&lruItem{key: key, entry: entry, expires: time.Now().Add(ttl)})
+
+	for _, tag := range entry.Tags {
+		if l.tags[tag] == nil {
+			l.tags[tag] = make(map[string]struct{})
+		}
+
+		l.tags[tag][key] = struct{}{}
+	}
+
+	for l.order.Len() > l.capacity {
+		l.remove(l.order.Back())
+	}
+
+	return nil
+}
+
+func (l *LRUCache) Invalidate(_ context.Context, tags ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range l.tags[tag] {
+			if elem, ok := l.entries[key]; ok {
+				l.remove(elem)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *LRUCache) remove(elem *list.Element) {
+	item := elem.Value.(*lruItem)
+
+	l.order.Remove(elem)
+	delete(l.entries, item.key)
+
+	for _, tag := range item.entry.Tags {
+		delete(l.tags[tag], item.key)
+
+		if len(l.tags[tag]) == 0 {
+			delete(l.tags, tag)
+		}
+	}
+}
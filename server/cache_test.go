@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := context.Background()
+	cache := NewLRUCache(2)
+
+	for _, key := range []string{"a", "b"} {
+		if err := cache.Set(c, key, &CacheEntry{Body: []byte(key), Tags: []string{"tag-" + key}}, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a is used last, so b is evicted by c.
+	if entry, _ := cache.Get(c, "a"); entry == nil {
+		t.Fatal("Get(a) = nil, want the entry")
+	}
+
+	_ = cache.Set(c, "c", &CacheEntry{Body: []byte("c"), Tags: []string{"tag-c"}}, time.Minute)
+
+	if entry, _ := cache.Get(c, "b"); entry != nil {
+		t.Errorf("Get(b) = %s, want it evicted", entry.Body)
+	}
+
+	if err := cache.Invalidate(c, "tag-a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry, _ := cache.Get(c, "a"); entry != nil {
+		t.Errorf("Get(a) = %s, want it invalidated", entry.Body)
+	}
+
+	if entry, _ := cache.Get(c, "c"); entry == nil {
+		t.Error("Get(c) = nil, want the entry of another tag")
+	}
+
+	_ = cache.Set(c, "expiring", &CacheEntry{}, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	if entry, _ := cache.Get(c, "expiring"); entry != nil {
+		t.Error("Get(expiring) returned an expired entry")
+	}
+}
+
+var products atomic.Int32
+
+type productRequest struct {
+	Control string `query:"control"`
+	Cookie  string `query:"cookie"`
+}
+
+// getProduct counts its calls, setting the Cache-Control and cookie of the query.
+//
+// @cache(1m, tag=products)
+// [GET] /products 200 json
+func getProduct(_ context.Context, req *Request, in *productRequest) (map[string]int32, error) {
+	if in.Control != "" {
+		req.SetHeader("Cache-Control", in.Control)
+	}
+
+	if in.Cookie != "" {
+		req.SetHeader("Set-Cookie", in.Cookie)
+	}
+
+	return map[string]int32{"product": products.Add(1)}, nil
+}
+
+// getNegotiatedProduct varies by Accept without saying it.
+//
+// @cache(1m)
+// [GET] /negotiated 200 negotiate(json,xml)
+func getNegotiatedProduct(_ context.Context, _ *Request, _ *struct{}) (*product, error) {
+	return &product{Name: "pen"}, nil
+}
+
+type product struct {
+	Name string `json:"name" xml:"name"`
+}
+
+// getUserProduct is cached per identity.
+//
+// @authorize
+// @cache(1m)
+// [GET] /user-products 200 json
+func getUserProduct(_ context.Context, req *Request, _ *struct{}) (map[string]string, error) {
+	identity, _ := req.LookupIdentity()
+
+	return map[string]string{"user": identity.ID}, nil
+}
+
+func TestCache(t *testing.T) {
+	s := New()
+	s.SetCacheBackend(NewLRUCache(0))
+	s.SetIdentifier(func(_ context.Context, req *Request, _ []string, _ ...string) {
+		req.SetIdentity(Identity{ID: string(req.GetHeader("X-User"))})
+	})
+	s.Register(NewAction(getProduct), NewAction(getNegotiatedProduct), NewAction(getUserProduct))
+
+	engine := s.Build().Engine
+	get := func(path string, headers ...ut.Header) *ut.ResponseRecorder {
+		return ut.PerformRequest(engine, http.MethodGet, path, nil, headers...)
+	}
+
+	first := get("/products")
+	etag := first.Header().Get("ETag")
+
+	if first.Code != http.StatusOK || etag == "" || first.Header().Get("Last-Modified") == "" {
+		t.Fatalf("first request = %d, ETag %q, Last-Modified %q", first.Code, etag, first.Header().Get("Last-Modified"))
+	}
+
+	if cached := get("/products"); cached.Body.String() != first.Body.String() || cached.Header().Get("Age") == "" {
+		t.Errorf("cached = %s, Age %q, want %s replayed", cached.Body.String(), cached.Header().Get("Age"), first.Body.String())
+	}
+
+	if res := get("/products", ut.Header{Key: "If-None-Match", Value: etag}); res.Code != http.StatusNotModified || res.Body.Len() != 0 {
+		t.Errorf("If-None-Match = %d %s, want 304", res.Code, res.Body.String())
+	}
+
+	if res := get("/products", ut.Header{Key: "If-None-Match", Value: `"other"`}); res.Code != http.StatusOK {
+		t.Errorf("If-None-Match of another ETag = %d, want 200", res.Code)
+	}
+
+	if res := get("/products", ut.Header{Key: "If-Modified-Since", Value: first.Header().Get("Last-Modified")}); res.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since = %d, want 304", res.Code)
+	}
+
+	if err := s.InvalidateCache(context.Background(), "products"); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := get("/products"); res.Body.String() == first.Body.String() {
+		t.Errorf("invalidated = %s, want a new response", res.Body.String())
+	}
+
+	// the responses forbidding storage or setting cookies are not cached.
+	for _, path := range []string{"/products?control=no-store", "/products?cookie=session%3D1"} {
+		if a, b := get(path), get(path); a.Body.String() == b.Body.String() {
+			t.Errorf("%s was cached: %s", path, b.Body.String())
+		}
+	}
+
+	// the negotiated responses vary by Accept.
+	jsonRes := get("/negotiated", ut.Header{Key: "Accept", Value: "application/json"})
+	xmlRes := get("/negotiated", ut.Header{Key: "Accept", Value: "application/xml"})
+
+	if !strings.HasPrefix(jsonRes.Body.String(), "{") || !strings.HasPrefix(xmlRes.Body.String(), "<") {
+		t.Errorf("negotiated = %s and %s, want json and xml", jsonRes.Body.String(), xmlRes.Body.String())
+	}
+
+	if vary := xmlRes.Header().Get("Vary"); vary != "Accept" {
+		t.Errorf("Vary = %q, want Accept once", vary)
+	}
+
+	// the responses of identities are private to them.
+	for _, user := range []string{"alice", "bob", "alice"} {
+		res := get("/user-products", ut.Header{Key: "X-User", Value: user})
+
+		if !strings.Contains(res.Body.String(), user) {
+			t.Errorf("response of %s = %s", user, res.Body.String())
+		}
+
+		if control := res.Header().Get("Cache-Control"); !strings.HasPrefix(control, "private") {
+			t.Errorf("Cache-Control of %s = %q, want private", user, control)
+		}
+	}
+}
+
+// streamProducts streams the products.
+//
+// @cache(1m)
+// [GET] /products/stream 200 sse
+func streamProducts(_ context.Context, _ *Request, _ *struct{}) (<-chan Event, error) {
+	return nil, nil
+}
+
+func TestCacheStreamed(t *testing.T) {
+	s := New()
+	s.Register(NewAction(streamProducts))
+
+	if err := s.Validate(); err == nil || !strings.Contains(err.Error(), "cannot be used with the sse responder") {
+		t.Errorf("Validate() = %v, want the cached sse route rejected", err)
+	}
+}
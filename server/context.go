@@ -187,6 +187,20 @@ func (req *Request) AbortWithError(code int, err error) *errors.Error {
 	return req.rc.AbortWithError(code, err)
 }
 
+// Fail aborts the request and passes err to the ErrorHandler of the server.
+// It is the way for middlewares, identifiers and decorators to reject a request.
+func (req *Request) Fail(c context.Context, err error) {
+	req.rc.Abort()
+
+	if s := serverOf(req.rc); s != nil {
+		s.fail(c, req.rc, err)
+
+		return
+	}
+
+	_ = req.rc.AbortWithError(AsHTTPError(err).Status, err)
+}
+
 // Method return request method.
 //
 // Returned value is valid until returning from RequestHandler.
@@ -3,28 +3,151 @@ package server
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/cloudwego/hertz/pkg/app"
 )
 
 type decoratorFn func(context.Context, *Request)
 
-var decorators = make(map[string]decoratorFn)
+// DecoratorFunc is a decorator, as created by a decorator factory.
+type DecoratorFunc = decoratorFn
+
+// decoratorFactory creates the decorator of the arguments of a `@name(arg1, arg2)` directive.
+type decoratorFactory func(args []string) (decoratorFn, error)
+
+func (s *Server) AddDecorator(name string, f decoratorFn) {
+	s.decorators[name] = f
+}
 
 func AddDecorator(name string, f decoratorFn) {
-	decorators[name] = f
+	defaultServer.AddDecorator(name, f)
 }
 
-func decorate(path, verb, decorator string) app.HandlerFunc {
-	return func(c context.Context, rctx *app.RequestContext) {
-		decorate, ok := decorators[decorator]
-		if !ok {
-			msg := fmt.Sprintf("%s decorator does not exist for [%s] %s", decorator, verb, path)
-			panic(msg)
-		}
+// AddDecoratorFactory registers a decorator taking arguments, such as `@cache(30s)`.
+// The factory is called once per directive of a route, when the server is validated,
+// and an error of it stops the build.
+//
+//	server.AddDecoratorFactory("cache", func(args []string) (server.DecoratorFunc, error) {
+//		ttl, err := time.ParseDuration(args[0])
+//		...
+//	})
+func (s *Server) AddDecoratorFactory(name string, factory func(args []string) (DecoratorFunc, error)) {
+	s.decoratorFactories[name] = factory
+}
+
+// AddDecoratorFactory registers a decorator taking arguments, such as `@cache(30s)`.
+func AddDecoratorFactory(name string, factory func(args []string) (DecoratorFunc, error)) {
+	defaultServer.AddDecoratorFactory(name, factory)
+}
+
+// decorate returns the handler of a decorator directive of the route. It panics if the
+// decorator does not exist or does not accept the arguments, so the build of the server fails.
+func (r *routeDescriber) decorate(directive string) app.HandlerFunc {
+	decorate, err := r.decorator(directive)
+	if err != nil {
+		panic(fmt.Sprintf("%s decorator of [%s] %s: %v", directive, r.Verb, r.FullPath(), err))
+	}
 
+	return func(c context.Context, rctx *app.RequestContext) {
 		req := &Request{rctx}
 
 		decorate(c, req)
 	}
 }
+
+// decorator returns the decorator of a directive of the route, created once by the
+// server and kept for the next calls.
+func (r *routeDescriber) decorator(directive string) (decoratorFn, error) {
+	if decorate, ok := r.decorated[directive]; ok {
+		return decorate, nil
+	}
+
+	decorate, err := r.server.decorator(r.factoryDirective(directive))
+	if err != nil {
+		return nil, err
+	}
+
+	if r.decorated == nil {
+		r.decorated = make(map[string]decoratorFn)
+	}
+
+	r.decorated[directive] = decorate
+
+	return decorate, nil
+}
+
+// factoryDirective returns the directive of the route given to the decorator factory.
+// The `@cache` of a route negotiating its content type varies by Accept, as its
+// responses do.
+func (r *routeDescriber) factoryDirective(directive string) string {
+	name, args, err := parseDecorator(directive)
+	if err != nil || name != "cache" || len(args) == 0 {
+		return directive
+	}
+
+	if _, ok := negotiateEncoders(r.ResponderType); !ok {
+		return directive
+	}
+
+	for _, arg := range args {
+		if name, value, _ := strings.Cut(arg, "="); strings.TrimSpace(name) == "vary" && strings.EqualFold(strings.TrimSpace(value), "Accept") {
+			return directive
+		}
+	}
+
+	return "cache(" + strings.Join(append(args, "vary=Accept"), ", ") + ")"
+}
+
+func (s *Server) decorator(directive string) (decoratorFn, error) {
+	name, args, err := parseDecorator(directive)
+	if err != nil {
+		return nil, err
+	}
+
+	if factory, ok := s.decoratorFactories[name]; ok {
+		return factory(args)
+	}
+
+	decorate, ok := s.decorators[name]
+	if !ok {
+		return nil, fmt.Errorf("%s decorator does not exist", name)
+	}
+
+	if args != nil {
+		return nil, fmt.Errorf("%s decorator takes no arguments", name)
+	}
+
+	return decorate, nil
+}
+
+// parseDecorator parses a `name` or `name(arg1, arg2)` decorator directive.
+func parseDecorator(directive string) (name string, args []string, err error) {
+	directive = strings.TrimSpace(directive)
+
+	name, rest, hasArgs := strings.Cut(directive, "(")
+	name = strings.TrimSpace(name)
+
+	if name == "" || strings.ContainsAny(name, " \t)") {
+		return "", nil, fmt.Errorf("invalid decorator %q", directive)
+	}
+
+	if !hasArgs {
+		return name, nil, nil
+	}
+
+	rest, ok := strings.CutSuffix(strings.TrimSpace(rest), ")")
+	if !ok || strings.ContainsAny(rest, "()") {
+		return "", nil, fmt.Errorf("invalid arguments of decorator %q", directive)
+	}
+
+	args = make([]string, 0)
+
+	for _, arg := range strings.Split(rest, ",") {
+		if arg = strings.TrimSpace(arg); arg != "" {
+			args = append(args, arg)
+		}
+	}
+
+	return name, args, nil
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+func TestParseDecorator(t *testing.T) {
+	tests := []struct {
+		directive string
+		name      string
+		args      []string
+		invalid   bool
+	}{
+		{directive: "logged", name: "logged"},
+		{directive: " cache(30s) ", name: "cache", args: []string{"30s"}},
+		{directive: "ratelimit(100/m, burst=10)", name: "ratelimit", args: []string{"100/m", "burst=10"}},
+		{directive: "cache()", name: "cache", args: []string{}},
+		{directive: "(30s)", invalid: true},
+		{directive: "cache(30s", invalid: true},
+		{directive: "cache(f(x))", invalid: true},
+		{directive: "two words", invalid: true},
+	}
+
+	for _, tt := range tests {
+		name, args, err := parseDecorator(tt.directive)
+		if (err != nil) != tt.invalid || name != tt.name || !slices.Equal(args, tt.args) || (args == nil) != (tt.args == nil) {
+			t.Errorf("parseDecorator(%q) = %q, %q, %v", tt.directive, name, args, err)
+		}
+	}
+}
+
+// tagged is tagged by a decorator factory.
+//
+// @tag(a, b)
+// @logged
+// [GET] /tagged 200 json
+func tagged(_ context.Context, _ *Request, _ *struct{}) (map[string]bool, error) {
+	return map[string]bool{"ok": true}, nil
+}
+
+func TestDecoratorFactory(t *testing.T) {
+	s := New()
+
+	var created [][]string
+
+	s.AddDecorator("logged", func(c context.Context, req *Request) {
+		req.SetHeader("X-Logged", "true")
+		req.Next(c)
+	})
+	s.AddDecoratorFactory("tag", func(args []string) (DecoratorFunc, error) {
+		created = append(created, args)
+		tag := strings.Join(args, "+")
+
+		return func(c context.Context, req *Request) {
+			req.SetHeader("X-Tag", tag)
+			req.Next(c)
+		}, nil
+	})
+	s.Register(NewAction(tagged))
+
+	engine := s.Build().Engine
+
+	res := ut.PerformRequest(engine, http.MethodGet, "/tagged", nil)
+	if res.Header().Get("X-Tag") != "a+b" || res.Header().Get("X-Logged") != "true" {
+		t.Errorf("headers = %v, want the decorators applied", res.Header())
+	}
+
+	ut.PerformRequest(engine, http.MethodGet, "/tagged", nil)
+
+	if fmt.Sprint(created) != "[[a b]]" {
+		t.Errorf("factory called with %v, want once with the arguments", created)
+	}
+}
+
+func TestDecoratorErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(s *Server)
+		problem string
+	}{
+		{
+			name:    "unknown",
+			setup:   func(s *Server) { s.AddDecorator("logged", func(context.Context, *Request) {}) },
+			problem: "@tag(a, b): tag decorator does not exist",
+		},
+		{
+			name: "arguments",
+			setup: func(s *Server) {
+				s.AddDecorator("logged", func(context.Context, *Request) {})
+				s.AddDecorator("tag", func(context.Context, *Request) {})
+			},
+			problem: "@tag(a, b): tag decorator takes no arguments",
+		},
+		{
+			name: "factory",
+			setup: func(s *Server) {
+				s.AddDecorator("logged", func(context.Context, *Request) {})
+				s.AddDecoratorFactory("tag", func([]string) (DecoratorFunc, error) {
+					return nil, errors.New("expected a single tag")
+				})
+			},
+			problem: "@tag(a, b): expected a single tag",
+		},
+	}
+
+	for _, tt := range tests {
+		s := New()
+		tt.setup(s)
+		s.Register(NewAction(tagged))
+
+		err := s.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.problem) {
+			t.Errorf("%s: Validate() = %v, want %q", tt.name, err, tt.problem)
+
+			continue
+		}
+
+		func() {
+			defer func() {
+				var validationErr *ValidationError
+				if r, _ := recover().(error); !errors.As(r, &validationErr) {
+					t.Errorf("%s: Build panicked with %v, want the *ValidationError", tt.name, r)
+				}
+			}()
+
+			s.Build()
+		}()
+	}
+}
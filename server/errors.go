@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/go-playground/validator/v10"
+)
+
+const problemContentType = "application/problem+json"
+
+// HTTPError is an error with an HTTP status, rendered as RFC 9457 problem details
+// by ProblemErrorHandler.
+type HTTPError struct {
+	Type     string       `json:"type,omitempty"`
+	Status   int          `json:"status"`
+	Code     string       `json:"code,omitempty"`
+	Title    string       `json:"title"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Fields   []FieldError `json:"errors,omitempty"`
+
+	err error
+}
+
+// FieldError describes why a single field of the request is not acceptable.
+// Errors of binding also report the part of the request the field is read from,
+// its raw value and the type it is expected to be.
+type FieldError struct {
+	Field    string `json:"field"`
+	Source   string `json:"source,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Message  string `json:"message"`
+}
+
+// NewHTTPError creates an HTTPError with the given status, titled by the status text.
+func NewHTTPError(status int, detail string) *HTTPError {
+	return &HTTPError{Status: status, Title: http.StatusText(status), Detail: detail}
+}
+
+func BadRequest(detail string) *HTTPError {
+	return NewHTTPError(http.StatusBadRequest, detail)
+}
+
+func Unauthorized(detail string) *HTTPError {
+	return NewHTTPError(http.StatusUnauthorized, detail)
+}
+
+func Forbidden(detail string) *HTTPError {
+	return NewHTTPError(http.StatusForbidden, detail)
+}
+
+func NotFound(detail string) *HTTPError {
+	return NewHTTPError(http.StatusNotFound, detail)
+}
+
+func Conflict(detail string) *HTTPError {
+	return NewHTTPError(http.StatusConflict, detail)
+}
+
+func Unprocessable(detail string) *HTTPError {
+	return NewHTTPError(http.StatusUnprocessableEntity, detail)
+}
+
+func TooManyRequests(detail string) *HTTPError {
+	return NewHTTPError(http.StatusTooManyRequests, detail)
+}
+
+func InternalServerError(detail string) *HTTPError {
+	return NewHTTPError(http.StatusInternalServerError, detail)
+}
+
+func ServiceUnavailable(detail string) *HTTPError {
+	return NewHTTPError(http.StatusServiceUnavailable, detail)
+}
+
+// WithCode sets an application specific error code.
+func (e *HTTPError) WithCode(code string) *HTTPError {
+	e.Code = code
+
+	return e
+}
+
+// WithField adds a field error.
+func (e *HTTPError) WithField(field, message string) *HTTPError {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+
+	return e
+}
+
+// Wrap sets the underlying error, which is never rendered to clients.
+func (e *HTTPError) Wrap(err error) *HTTPError {
+	e.err = err
+
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("%d %s", e.Status, e.Title)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+
+	if e.err != nil {
+		msg += ": " + e.err.Error()
+	}
+
+	return msg
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.err
+}
+
+// AsHTTPError converts err to an HTTPError. Bind errors become 422 and validation
+// errors 400, with an error per field. Errors which are not an HTTPError become 500.
+func AsHTTPError(err error) *HTTPError {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	var bindErr *BindError
+	if errors.As(err, &bindErr) {
+		httpErr = Unprocessable("request binding failed").Wrap(err)
+		httpErr.Fields = bindErr.Fields
+
+		return httpErr
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		httpErr = BadRequest("request validation failed").Wrap(err)
+		for _, fe := range validationErrs {
+			httpErr.WithField(fe.Field(), validationMessage(fe))
+		}
+
+		return httpErr
+	}
+
+	return InternalServerError("").Wrap(err)
+}
+
+// ProblemErrorHandler renders err as an RFC 9457 application/problem+json response.
+// It is the default ErrorHandler of a Server.
+func ProblemErrorHandler(_ context.Context, rctx *app.RequestContext, err error) {
+	problem := *AsHTTPError(err)
+	if problem.Instance == "" {
+		problem.Instance = string(rctx.Path())
+	}
+
+	body, jsonErr := json.Marshal(problem)
+	if jsonErr != nil {
+		rctx.AbortWithStatus(problem.Status)
+
+		return
+	}
+
+	rctx.Abort()
+	rctx.Data(problem.Status, problemContentType, body)
+}
+
+// fail passes err to the error handler of the server.
+func (s *Server) fail(c context.Context, rctx *app.RequestContext, err error) {
+	if s.handleError == nil {
+		_ = rctx.AbortWithError(AsHTTPError(err).Status, err)
+
+		return
+	}
+
+	s.handleError(c, rctx, err)
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "uri":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "oneof":
+		return "must be one of " + fe.Param()
+	case "len":
+		return "must have length " + fe.Param()
+	case "min", "gte":
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		return "must be at most " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "lt":
+		return "must be less than " + fe.Param()
+	}
+
+	return fmt.Sprintf("failed on the '%s' validation", fe.Tag())
+}
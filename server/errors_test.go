@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+type signupRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Age   int    `json:"age" validate:"min=18"`
+}
+
+// signup fails with the problem of its email.
+//
+// [POST] /signup 201 json
+func signup(_ context.Context, _ *Request, in *signupRequest) (map[string]bool, error) {
+	switch in.Email {
+	case "taken@example.com":
+		return nil, Conflict("the email is taken").WithCode("email_taken").WithField("email", "is taken").
+			Wrap(errors.New("duplicate key users_email"))
+	case "crash@example.com":
+		return nil, errors.New("connection refused by db-internal:5432")
+	}
+
+	return map[string]bool{"ok": true}, nil
+}
+
+func TestProblemErrorHandler(t *testing.T) {
+	s := New()
+	s.Register(NewAction(signup))
+
+	engine := s.Build().Engine
+	post := func(body string) (*ut.ResponseRecorder, HTTPError) {
+		res := ut.PerformRequest(engine, http.MethodPost, "/signup", &ut.Body{Body: strings.NewReader(body), Len: len(body)},
+			ut.Header{Key: "Content-Type", Value: "application/json"})
+
+		var problem HTTPError
+		_ = json.Unmarshal(res.Body.Bytes(), &problem)
+
+		return res, problem
+	}
+
+	res, problem := post(`{"email":"taken@example.com","age":20}`)
+	if res.Code != http.StatusConflict || res.Header().Get("Content-Type") != problemContentType {
+		t.Fatalf("conflict = %d %s", res.Code, res.Header().Get("Content-Type"))
+	}
+
+	want := HTTPError{
+		Status: http.StatusConflict, Code: "email_taken", Title: "Conflict", Detail: "the email is taken",
+		Instance: "/signup", Fields: []FieldError{{Field: "email", Message: "is taken"}},
+	}
+	if fmt.Sprint(problem) != fmt.Sprint(want) {
+		t.Errorf("problem = %+v, want %+v", problem, want)
+	}
+
+	if strings.Contains(res.Body.String(), "users_email") {
+		t.Errorf("problem = %s, want the wrapped error hidden", res.Body.String())
+	}
+
+	res, problem = post(`{"email":"crash@example.com","age":20}`)
+	if res.Code != http.StatusInternalServerError || problem.Detail != "" || strings.Contains(res.Body.String(), "db-internal") {
+		t.Errorf("internal error = %d %s, want a 500 without detail", res.Code, res.Body.String())
+	}
+
+	res, problem = post(`{"email":"ann","age":12}`)
+	if res.Code != http.StatusBadRequest || len(problem.Fields) != 2 {
+		t.Fatalf("invalid request = %d %s, want 400 with 2 fields", res.Code, res.Body.String())
+	}
+
+	if email := problem.Fields[0]; email.Field != "email" || email.Message != "must be a valid email address" {
+		t.Errorf("email field = %+v", email)
+	}
+
+	if age := problem.Fields[1]; age.Field != "age" || age.Message != "must be at least 18" {
+		t.Errorf("age field = %+v", age)
+	}
+}
+
+func TestAsHTTPError(t *testing.T) {
+	conflict := Conflict("taken")
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		fields int
+	}{
+		{name: "http error", err: conflict, status: http.StatusConflict},
+		{name: "wrapped http error", err: fmt.Errorf("creating: %w", conflict), status: http.StatusConflict},
+		{name: "bind error", err: &BindError{Fields: []FieldError{{Field: "id"}}, err: errors.New("bad id")}, status: http.StatusUnprocessableEntity, fields: 1},
+		{name: "validation errors", err: validate.Struct(&signupRequest{Age: 1}), status: http.StatusBadRequest, fields: 2},
+		{name: "other error", err: errors.New("boom"), status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		httpErr := AsHTTPError(tt.err)
+
+		if httpErr.Status != tt.status || len(httpErr.Fields) != tt.fields {
+			t.Errorf("%s: AsHTTPError = %d with %d fields, want %d with %d", tt.name, httpErr.Status, len(httpErr.Fields), tt.status, tt.fields)
+		}
+
+		// the HTTPError is kept or wraps the error.
+		if !strings.Contains(tt.err.Error(), httpErr.Error()) && !strings.Contains(httpErr.Error(), tt.err.Error()) {
+			t.Errorf("%s: AsHTTPError = %v, want it to keep %v", tt.name, httpErr, tt.err)
+		}
+	}
+
+	if AsHTTPError(fmt.Errorf("creating: %w", conflict)) != conflict {
+		t.Error("AsHTTPError of a wrapped HTTPError is not the HTTPError")
+	}
+}
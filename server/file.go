@@ -0,0 +1,388 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	herrors "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// File is an uploaded file of a multipart form, bound to the `*server.File` and
+// `[]*server.File` fields of an IN struct.
+//
+//	type UpdateAvatarRequest struct {
+//		Avatar *server.File   `form:"avatar" maxsize:"5MB" mime:"image/png,image/jpeg" validate:"required"`
+//		Photos []*server.File `form:"photos" maxsize:"10MB" mime:"image/*"`
+//	}
+//
+// The size and the MIME type sniffed from the content are checked before the handler
+// is called. Larger files are rejected with 413 and files of other types with 415.
+type File struct {
+	// Filename is the name of the file sent by the client.
+	Filename string
+	Size     int64
+	// ContentType is the MIME type detected from the content, not the one sent by the client.
+	ContentType string
+	Header      *multipart.FileHeader
+}
+
+// Open opens the content of the file.
+func (f *File) Open() (multipart.File, error) {
+	return f.Header.Open()
+}
+
+// Parts reads the parts of a multipart request as they arrive, without buffering
+// them, when it is the `*server.Parts` field of an IN struct. The server must be
+// built with WithStreamBody(true) for the body to be streamed from the connection.
+//
+//	type UploadRequest struct {
+//		Parts *server.Parts `maxsize:"1GB" mime:"video/*"`
+//	}
+//
+//	for {
+//		part, err := in.Parts.Next()
+//		if errors.Is(err, io.EOF) {
+//			break
+//		}
+//		...
+//		io.Copy(dst, part)
+//	}
+//
+// The maxsize and mime tags apply to each file part. Next returns a 415 error for
+// a part of another type, and reading a part beyond maxsize returns a 413 error,
+// so the handler can return them as they are.
+type Parts struct {
+	reader  *multipart.Reader
+	maxSize int64
+	mime    []string
+}
+
+// Part is a part of a streamed multipart request.
+type Part struct {
+	// FormName is the name of the form field of the part.
+	FormName string
+	// Filename is the file name of a file part, empty for the other fields.
+	Filename string
+	// ContentType is the MIME type detected from the content of a file part.
+	ContentType string
+	Header      textproto.MIMEHeader
+
+	r io.Reader
+}
+
+// Read reads the content of the part.
+func (p *Part) Read(b []byte) (int, error) {
+	return p.r.Read(b)
+}
+
+// Next returns the next part, or io.EOF when there are no more parts. It discards
+// the rest of the previous part.
+func (p *Parts) Next() (*Part, error) {
+	mp, err := p.reader.NextPart()
+	if err != nil {
+		return nil, err
+	}
+
+	part := &Part{FormName: mp.FormName(), Filename: mp.FileName(), Header: mp.Header, r: mp}
+	if part.Filename == "" {
+		return part, nil
+	}
+
+	buffered := bufio.NewReaderSize(mp, sniffSize)
+
+	head, err := buffered.Peek(sniffSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+
+	detected := mimetype.Detect(head)
+	part.ContentType = detected.String()
+
+	if !mimeAllowed(detected, p.mime) {
+		return nil, unsupportedFileType(part.FormName, part.ContentType, p.mime)
+	}
+
+	part.r = buffered
+	if p.maxSize > 0 {
+		part.r = &limitedPart{r: buffered, field: part.FormName, max: p.maxSize}
+	}
+
+	return part, nil
+}
+
+type limitedPart struct {
+	r     io.Reader
+	field string
+	max   int64
+	read  int64
+}
+
+// Read reads up to one byte past max, to tell a part of max bytes from a larger one,
+// and does not return the bytes past max.
+func (l *limitedPart) Read(b []byte) (int, error) {
+	if remaining := l.max - l.read + 1; int64(len(b)) > remaining {
+		b = b[:remaining]
+	}
+
+	n, err := l.r.Read(b)
+	if l.read += int64(n); l.read > l.max {
+		return n - int(l.read-l.max), fileTooLarge(l.field, l.max)
+	}
+
+	return n, err
+}
+
+// streamedKey marks the requests whose body is read by a Parts field, so the
+// decorators do not buffer it.
+const streamedKey = "streamed"
+
+func markStreamed(_ context.Context, rctx *app.RequestContext) {
+	rctx.Set(streamedKey, true)
+}
+
+// sniffSize is the length of the content read to detect the MIME type of a file.
+const sniffSize = 3072
+
+type fileField struct {
+	index   []int
+	name    string
+	kind    reflect.Type
+	maxSize int64
+	mime    []string
+}
+
+var (
+	fileType  = reflect.TypeOf((*File)(nil))
+	filesType = reflect.TypeOf([]*File(nil))
+	partsType = reflect.TypeOf((*Parts)(nil))
+)
+
+// fileFieldsOf returns the file and parts fields of the in struct, or an error for
+// an invalid maxsize tag.
+func fileFieldsOf(in reflect.Type) (fields fileFields, err error) {
+	for in.Kind() == reflect.Pointer {
+		in = in.Elem()
+	}
+
+	if in.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	for _, f := range reflect.VisibleFields(in) {
+		if f.Type != fileType && f.Type != filesType && f.Type != partsType {
+			continue
+		}
+
+		field := fileField{index: f.Index, name: f.Name, kind: f.Type}
+
+		if name, _, _ := strings.Cut(f.Tag.Get("form"), ","); name != "" {
+			field.name = name
+		}
+
+		if tag := f.Tag.Get("maxsize"); tag != "" {
+			size, err := parseSize(tag)
+			if err != nil {
+				return nil, fmt.Errorf("maxsize of %s.%s: %w", in.Name(), f.Name, err)
+			}
+
+			field.maxSize = size
+		}
+
+		for _, t := range strings.Split(f.Tag.Get("mime"), ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				field.mime = append(field.mime, t)
+			}
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+type fileFields []fileField
+
+// streamed reports whether the input has a Parts field.
+func (fields fileFields) streamed() bool {
+	for _, field := range fields {
+		if field.kind == partsType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseSize parses a size such as 512, 100KB, 5MB or 1GB, in multiples of 1024.
+func parseSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+
+	multiplier := int64(1)
+
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"B", 1}} {
+		if number, ok := strings.CutSuffix(s, unit.suffix); ok {
+			s, multiplier = strings.TrimSpace(number), unit.size
+
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+
+	return n * multiplier, nil
+}
+
+// bindFiles sets the file and parts fields of the bound input.
+func bindFiles(rctx *app.RequestContext, fields fileFields, in any) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	v := reflect.ValueOf(in)
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	for _, field := range fields {
+		// the binder sets empty structs to the fields it cannot bind.
+		v.FieldByIndex(field.index).SetZero()
+
+		if field.kind == partsType {
+			parts, err := newParts(rctx, field)
+			if err != nil {
+				return err
+			}
+
+			v.FieldByIndex(field.index).Set(reflect.ValueOf(parts))
+
+			continue
+		}
+
+		form, err := rctx.MultipartForm()
+		if err != nil {
+			if errors.Is(err, herrors.ErrNoMultipartForm) {
+				continue
+			}
+
+			return BadRequest("invalid multipart form").Wrap(err)
+		}
+
+		var files []*File
+
+		for _, header := range form.File[field.name] {
+			file, err := newFile(header, field)
+			if err != nil {
+				return err
+			}
+
+			files = append(files, file)
+		}
+
+		if len(files) == 0 {
+			continue
+		}
+
+		if field.kind == fileType {
+			v.FieldByIndex(field.index).Set(reflect.ValueOf(files[0]))
+		} else {
+			v.FieldByIndex(field.index).Set(reflect.ValueOf(files))
+		}
+	}
+
+	return nil
+}
+
+func newFile(header *multipart.FileHeader, field fileField) (*File, error) {
+	if field.maxSize > 0 && header.Size > field.maxSize {
+		return nil, fileTooLarge(field.name, field.maxSize)
+	}
+
+	content, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	detected, err := mimetype.DetectReader(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if !mimeAllowed(detected, field.mime) {
+		return nil, unsupportedFileType(field.name, detected.String(), field.mime)
+	}
+
+	return &File{Filename: header.Filename, Size: header.Size, ContentType: detected.String(), Header: header}, nil
+}
+
+func newParts(rctx *app.RequestContext, field fileField) (*Parts, error) {
+	mediaType, params, err := mime.ParseMediaType(string(rctx.Request.Header.ContentType()))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return nil, NewHTTPError(http.StatusUnsupportedMediaType, "the request is not multipart").
+			WithField(field.name, "must be multipart/form-data")
+	}
+
+	// the body is only a stream on the servers built with WithStreamBody(true), and
+	// is already read otherwise.
+	body := rctx.RequestBodyStream()
+	if !rctx.Request.IsBodyStream() {
+		body = bytes.NewReader(rctx.Request.Body())
+	}
+
+	return &Parts{
+		reader:  multipart.NewReader(body, params["boundary"]),
+		maxSize: field.maxSize,
+		mime:    field.mime,
+	}, nil
+}
+
+// mimeAllowed reports whether the detected type is one of the allowed types. The parents
+// of the detected type are not matched, so text/plain does not allow an html file nor
+// application/zip a docx file. A `type/*` pattern allows the subtypes of type, and no
+// allowed types allow any type.
+func mimeAllowed(detected *mimetype.MIME, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, pattern := range allowed {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(detected.String(), prefix+"/") {
+				return true
+			}
+		} else if detected.Is(pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func fileTooLarge(field string, max int64) *HTTPError {
+	return NewHTTPError(http.StatusRequestEntityTooLarge, "the file is too large").
+		WithField(field, fmt.Sprintf("must be at most %d bytes", max))
+}
+
+func unsupportedFileType(field, detected string, allowed []string) *HTTPError {
+	return NewHTTPError(http.StatusUnsupportedMediaType, "the file type is not supported").
+		WithField(field, fmt.Sprintf("is %s, must be %s", detected, strings.Join(allowed, " or ")))
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/gabriel-vasile/mimetype"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{"512": 512, "100KB": 100 << 10, "5 mb": 5 << 20, "1GB": 1 << 30, "64B": 64}
+
+	for size, want := range tests {
+		if got, err := parseSize(size); err != nil || got != want {
+			t.Errorf("parseSize(%q) = %d, %v, want %d", size, got, err, want)
+		}
+	}
+
+	for _, size := range []string{"", "0", "-1KB", "5TB", "MB"} {
+		if _, err := parseSize(size); err == nil {
+			t.Errorf("parseSize(%q) succeeded, want an error", size)
+		}
+	}
+}
+
+func TestMimeAllowed(t *testing.T) {
+	png := mimetype.Detect([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"))
+	html := mimetype.Detect([]byte("<!DOCTYPE html><html><body>hi</body></html>"))
+
+	tests := []struct {
+		detected *mimetype.MIME
+		allowed  []string
+		want     bool
+	}{
+		{detected: png, allowed: nil, want: true},
+		{detected: png, allowed: []string{"image/png"}, want: true},
+		{detected: png, allowed: []string{"image/*"}, want: true},
+		{detected: png, allowed: []string{"text/*", "image/jpeg"}, want: false},
+		// the parents of the detected type are not matched.
+		{detected: html, allowed: []string{"text/plain"}, want: false},
+		{detected: html, allowed: []string{"text/*"}, want: true},
+		{detected: html, allowed: []string{"image/*"}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := mimeAllowed(tt.detected, tt.allowed); got != tt.want {
+			t.Errorf("mimeAllowed(%s, %q) = %t, want %t", tt.detected, tt.allowed, got, tt.want)
+		}
+	}
+}
+
+func TestLimitedPart(t *testing.T) {
+	for _, size := range []int{8, 9, 64} {
+		part := &limitedPart{r: strings.NewReader(strings.Repeat("x", size)), field: "video", max: 8}
+
+		content, err := io.ReadAll(part)
+		if len(content) > 8 {
+			t.Errorf("read %d bytes of a part limited to 8", len(content))
+		}
+
+		var httpErr *HTTPError
+		if tooLarge := errors.As(err, &httpErr) && httpErr.Status == http.StatusRequestEntityTooLarge; tooLarge != (size > 8) {
+			t.Errorf("part of %d bytes: err = %v", size, err)
+		}
+	}
+}
+
+// multipartBody returns a multipart body of the files by form name, and its content type.
+func multipartBody(t *testing.T, files map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+
+	var body bytes.Buffer
+
+	w := multipart.NewWriter(&body)
+
+	for name, content := range files {
+		part, err := w.CreateFormFile(name, name+".bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, _ = part.Write([]byte(content))
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &body, w.FormDataContentType()
+}
+
+// uploadDocument accepts a text document of at most 16 bytes.
+//
+// [POST] /documents 201 json
+func uploadDocument(_ context.Context, _ *Request, in *struct {
+	Document *File `form:"document" maxsize:"16B" mime:"text/*"`
+}) (map[string]any, error) {
+	return map[string]any{"type": in.Document.ContentType, "size": in.Document.Size}, nil
+}
+
+func TestFileFields(t *testing.T) {
+	s := New()
+	s.Register(NewAction(uploadDocument))
+
+	engine := s.Build().Engine
+
+	tests := []struct {
+		content string
+		status  int
+	}{
+		{content: "plain text", status: http.StatusCreated},
+		{content: strings.Repeat("x", 17), status: http.StatusRequestEntityTooLarge},
+		{content: "\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR", status: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		body, contentType := multipartBody(t, map[string]string{"document": tt.content})
+		res := ut.PerformRequest(engine, http.MethodPost, "/documents", &ut.Body{Body: body, Len: body.Len()},
+			ut.Header{Key: "Content-Type", Value: contentType})
+
+		if res.Code != tt.status {
+			t.Errorf("upload of %q = %d %s, want %d", tt.content, res.Code, res.Body.String(), tt.status)
+		}
+	}
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r    io.Reader
+	read atomic.Int64
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.read.Add(int64(n))
+
+	return n, err
+}
+
+var uploadBody *countingReader
+
+// uploadVideo streams a video part, and reports whether the body was read before.
+//
+// @idempotent
+// [POST] /videos 201 json
+func uploadVideo(_ context.Context, _ *Request, in *struct {
+	Parts *Parts `maxsize:"1KB"`
+}) (map[string]any, error) {
+	buffered := uploadBody.read.Load() > 0
+
+	part, err := in.Parts.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := io.ReadAll(part)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"buffered": buffered, "size": len(content)}, nil
+}
+
+func TestPartsIdempotent(t *testing.T) {
+	s := New()
+	s.Register(NewAction(uploadVideo))
+
+	engine := s.Build().Engine
+
+	body, contentType := multipartBody(t, map[string]string{"video": strings.Repeat("v", 100)})
+	uploadBody = &countingReader{r: body}
+
+	// a body of length -1 is streamed.
+	res := ut.PerformRequest(engine, http.MethodPost, "/videos", &ut.Body{Body: uploadBody, Len: -1},
+		ut.Header{Key: "Content-Type", Value: contentType}, ut.Header{Key: "Idempotency-Key", Value: "k1"})
+
+	if res.Code != http.StatusCreated || res.Body.String() != `{"buffered":false,"size":100}` {
+		t.Errorf("upload = %d %s, want the part streamed to the handler", res.Code, res.Body.String())
+	}
+}
@@ -2,11 +2,8 @@ package server
 
 import (
 	"go/ast"
-	"go/doc"
 	"go/parser"
 	"go/token"
-	"log"
-	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
@@ -16,41 +13,68 @@ func runtimeFunc(f interface{}) *runtime.Func {
 	return runtime.FuncForPC(reflect.ValueOf(f).Pointer())
 }
 
+var descriptions = make(map[string]string)
+
+// AddDescriptions registers doc comments of handlers keyed by their runtime function name.
+// It is called by the code generated with cmd/hertzgen, so Register does not need
+// the handler's source file at runtime.
+func AddDescriptions(funcDescriptions map[string]string) {
+	for name, description := range funcDescriptions {
+		descriptions[name] = description
+	}
+}
+
+// funcDescription returns the doc comment of the function, registered by AddDescriptions
+// or read from its source file. A method value is an autogenerated wrapper without
+// source, so its doc comment must be generated by cmd/hertzgen.
 func funcDescription(f interface{}) string {
 	fn := runtimeFunc(f)
-	fileName, _ := fn.FileLine(0)
-	splitedFuncName := strings.Split(fn.Name(), ".")
-	funcName := splitedFuncName[len(splitedFuncName)-1]
-	fset := token.NewFileSet()
+	name := strings.TrimSuffix(fn.Name(), "-fm")
 
-	// Parse src
-	parsedAst, err := parser.ParseFile(fset, fileName, nil, parser.ParseComments)
-	if err != nil {
-		log.Fatal(err)
+	if description, ok := descriptions[name]; ok {
+		return description
+	}
+
+	fileName, _ := fn.FileLine(fn.Entry())
 
+	parsedAst, err := parser.ParseFile(token.NewFileSet(), fileName, nil, parser.ParseComments)
+	if err != nil {
 		return ""
 	}
 
-	pkg := &ast.Package{ //nolint
-		Name:  "Any",
-		Files: make(map[string]*ast.File),
+	for _, decl := range parsedAst.Decls {
+		if fd, ok := decl.(*ast.FuncDecl); ok && fd.Doc != nil && strings.HasSuffix(name, "."+declName(fd)) {
+			return fd.Doc.Text()
+		}
+	}
+
+	return ""
+}
+
+// declName returns the name of the function declaration as it ends its runtime name,
+// F, (*T).M or T.M.
+func declName(fd *ast.FuncDecl) string {
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return fd.Name.Name
 	}
-	pkg.Files[fileName] = parsedAst
-	importPath, _ := filepath.Abs("/")
 
-	myDoc := doc.New(pkg, importPath, doc.AllDecls)
-	for _, theFunc := range myDoc.Funcs {
-		if theFunc.Name == funcName {
-			return theFunc.Doc
-		}
+	recv := fd.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		return "(*" + recvName(star.X) + ")." + fd.Name.Name
 	}
 
-	for _, theType := range myDoc.Types {
-		for _, theFunc := range theType.Funcs {
-			if theFunc.Name == funcName {
-				return theFunc.Doc
-			}
-		}
+	return recvName(recv) + "." + fd.Name.Name
+}
+
+// recvName returns the name of a receiver type, with [...] for the type params.
+func recvName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return recvName(t.X) + "[...]"
+	case *ast.IndexListExpr:
+		return recvName(t.X) + "[...]"
 	}
 
 	return ""
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type accounts struct{}
+
+// get is a method of a pointer receiver.
+//
+// [GET] /accounts/:id 200 json
+func (*accounts) get(_ context.Context, _ *Request, _ *struct{}) (*Identity, error) {
+	return nil, nil
+}
+
+// list is a method of a value receiver.
+//
+// [GET] /accounts 200 json
+func (accounts) list(_ context.Context, _ *Request, _ *struct{}) ([]Identity, error) {
+	return nil, nil
+}
+
+// getIdentity returns a type declared in another file.
+//
+// [GET] /identity 200 json
+func getIdentity(_ context.Context, _ *Request, _ *struct{}) (Identity, error) {
+	return Identity{}, nil
+}
+
+func TestFuncDescription(t *testing.T) {
+	var a accounts
+
+	// the method values are autogenerated wrappers, whose source cannot be read.
+	tests := []struct {
+		name        string
+		fn          any
+		runtimeName string
+		want        string
+	}{
+		{name: "function", fn: getIdentity, runtimeName: "server.getIdentity", want: "[GET] /identity 200 json"},
+		{name: "pointer method", fn: (*accounts).get, runtimeName: "server.(*accounts).get", want: "[GET] /accounts/:id 200 json"},
+		{name: "value method", fn: accounts.list, runtimeName: "server.accounts.list", want: "[GET] /accounts 200 json"},
+		{name: "pointer method value", fn: (&a).get, runtimeName: "server.(*accounts).get-fm"},
+		{name: "value method value", fn: a.list, runtimeName: "server.accounts.list-fm"},
+	}
+
+	for _, tt := range tests {
+		if name := runtimeFunc(tt.fn).Name(); !strings.HasSuffix(name, tt.runtimeName) {
+			t.Errorf("%s: runtime name = %s, want %s", tt.name, name, tt.runtimeName)
+		}
+
+		if got := funcDescription(tt.fn); tt.want != "" && !strings.Contains(got, tt.want) {
+			t.Errorf("%s: funcDescription = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFuncDescriptionGenerated(t *testing.T) {
+	// the names written by hertzgen have no -fm suffix.
+	const name = "github.com/maadiii/hertz/server.accounts.list"
+
+	AddDescriptions(map[string]string{name: "[GET] /generated 200 json\n"})
+	defer delete(descriptions, name)
+
+	var a accounts
+
+	for _, fn := range []any{accounts.list, a.list} {
+		if got := funcDescription(fn); got != "[GET] /generated 200 json\n" {
+			t.Errorf("funcDescription(%s) = %q, want the generated describer", runtimeFunc(fn).Name(), got)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+// RouterGroup is a set of routes sharing a path prefix, middlewares,
+// default @authorize roles and permissions and decorators.
+type RouterGroup struct {
+	server     *Server
+	prefix     string
+	parent     *RouterGroup
+	handlers   []app.HandlerFunc
+	authorize  *identifierDescriber
+	decorators []string
+}
+
+// Group creates a new router group of the default Server. Handlers are registered in the group by
+// Register(handler, group) or by the `@group /prefix` comment directive.
+//
+//	v1 := server.Group("/api/v1", logger)
+//	server.Register(GetUser, v1)
+func Group(relativePath string, handlers ...app.HandlerFunc) *RouterGroup {
+	return defaultServer.Group(relativePath, handlers...)
+}
+
+// Group creates a new router group.
+func (s *Server) Group(relativePath string, handlers ...app.HandlerFunc) *RouterGroup {
+	return s.root.Group(relativePath, handlers...)
+}
+
+// Group creates a new router group nested in g.
+func (g *RouterGroup) Group(relativePath string, handlers ...app.HandlerFunc) *RouterGroup {
+	group := &RouterGroup{
+		server:   g.server,
+		prefix:   strings.TrimRight(relativePath, "/"),
+		parent:   g,
+		handlers: handlers,
+	}
+
+	// the `@group` directives name a group by its base path, so it is created once.
+	if _, ok := g.server.groups[group.BasePath()]; ok {
+		g.server.duplicateGroups = append(g.server.duplicateGroups, group.BasePath())
+	} else {
+		g.server.groups[group.BasePath()] = group
+	}
+
+	return group
+}
+
+// BasePath returns the full path prefix of the group.
+func (g *RouterGroup) BasePath() string {
+	if g.parent == nil {
+		return g.prefix
+	}
+
+	return g.parent.BasePath() + g.prefix
+}
+
+// Register registers the actions in the group.
+func (g *RouterGroup) Register(actions ...Action) {
+	for _, action := range actions {
+		g.server.add(action.route, g)
+	}
+}
+
+// Use adds middlewares to the group.
+func (g *RouterGroup) Use(handlers ...app.HandlerFunc) *RouterGroup {
+	g.handlers = append(g.handlers, handlers...)
+
+	return g
+}
+
+// Authorize sets the roles and permissions of handlers in the group which have no @authorize directive.
+// An identity needs any of the roles and any of the permissions.
+func (g *RouterGroup) Authorize(roles []string, permissions ...string) *RouterGroup {
+	g.authorize = newIdentifierDescriber(roles, permissions)
+
+	return g
+}
+
+// AuthorizeExpr sets the @authorize expression of handlers in the group which have no
+// @authorize directive. It panics if the expression is invalid.
+//
+//	g.AuthorizeExpr("role:admin || perm:orders.*")
+func (g *RouterGroup) AuthorizeExpr(expression string) *RouterGroup {
+	authorize, err := parseAuthorize(expression)
+	if err != nil {
+		panic(fmt.Sprintf("group %s: %v", g.BasePath(), err))
+	}
+
+	g.authorize = authorize
+
+	return g
+}
+
+// Decorate adds decorators running before the decorators of every handler in the group.
+// It panics if a decorator directive is invalid, such as `cache(30s`.
+func (g *RouterGroup) Decorate(decorators ...string) *RouterGroup {
+	for _, decorator := range decorators {
+		if _, _, err := parseDecorator(decorator); err != nil {
+			panic(fmt.Sprintf("group %s: %v", g.BasePath(), err))
+		}
+	}
+
+	g.decorators = append(g.decorators, decorators...)
+
+	return g
+}
+
+func (g *RouterGroup) defaultAuthorize() *identifierDescriber {
+	for group := g; group != nil; group = group.parent {
+		if group.authorize != nil {
+			return group.authorize
+		}
+	}
+
+	return nil
+}
+
+func (g *RouterGroup) allDecorators() []string {
+	if g.parent == nil {
+		return g.decorators
+	}
+
+	return slices.Concat(g.parent.allDecorators(), g.decorators)
+}
+
+// mount returns the hertz router group of g, creating its parents in mounted as needed.
+func (g *RouterGroup) mount(mounted map[*RouterGroup]*route.RouterGroup) *route.RouterGroup {
+	if rg, ok := mounted[g]; ok {
+		return rg
+	}
+
+	rg := g.parent.mount(mounted).Group(g.prefix, g.handlers...)
+	mounted[g] = rg
+
+	return rg
+}
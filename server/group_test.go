@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+// listReports is registered in the first /admin group.
+//
+// @group /admin
+// [GET] /reports 200 json
+func listReports(_ context.Context, _ *Request, _ *struct{}) (map[string]bool, error) {
+	return map[string]bool{"ok": true}, nil
+}
+
+func TestGroupDuplicates(t *testing.T) {
+	s := New()
+	s.Group("/admin", func(_ context.Context, rctx *app.RequestContext) {
+		rctx.Header("X-Group", "first")
+	})
+	s.Group("/admin")
+	s.Group("/api").Group("/v1")
+	s.Group("/api/v1")
+	s.Group("/api/v2")
+	s.Register(NewAction(listReports))
+
+	err := s.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want the duplicate groups")
+	}
+
+	for _, path := range []string{"/admin", "/api/v1"} {
+		if !strings.Contains(err.Error(), "group "+path+": it is created more than once") {
+			t.Errorf("Validate() = %v, want the duplicate group %s", err, path)
+		}
+	}
+
+	if strings.Contains(err.Error(), "/api/v2") || strings.Contains(err.Error(), "group /api:") {
+		t.Errorf("Validate() = %v, want the groups created once accepted", err)
+	}
+
+	// the groups created again do not replace the first ones.
+	s = New()
+	for _, name := range []string{"first", "second"} {
+		s.Group("/admin", func(_ context.Context, rctx *app.RequestContext) {
+			rctx.Header("X-Group", name)
+		})
+	}
+
+	s.Register(NewAction(listReports))
+
+	// Build panics on the duplicate, which is ignored here.
+	s.duplicateGroups = nil
+
+	if res := ut.PerformRequest(s.Build().Engine, http.MethodGet, "/admin/reports", nil); res.Header().Get("X-Group") != "first" {
+		t.Errorf("X-Group = %q, want the route in the first group", res.Header().Get("X-Group"))
+	}
+}
@@ -2,40 +2,107 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/go-playground/validator/v10"
 )
 
-func Register[IN any, OUT any](action func(context.Context, *Request, IN) (OUT, error)) {
+// Register registers the action described by its doc comment in the default Server.
+// The action is registered in the given groups, otherwise in the group of its
+// `@group /prefix` directive or in the root router.
+func Register[IN any, OUT any](action func(context.Context, *Request, IN) (OUT, error), groups ...*RouterGroup) {
+	a := NewAction(action)
+
+	if len(groups) == 0 {
+		defaultServer.Register(a)
+
+		return
+	}
+
+	for _, group := range groups {
+		group.Register(a)
+	}
+}
+
+// Action is a handler described by its doc comment, ready to be registered on a Server or RouterGroup.
+type Action struct {
+	route *routeDescriber
+}
+
+// NewAction parses the doc comment of the action.
+//
+//	s := server.New()
+//	s.Register(server.NewAction(GetUser))
+func NewAction[IN any, OUT any](action func(context.Context, *Request, IN) (OUT, error)) Action {
 	handler := &Handler[IN, OUT]{HandlerFn: action}
 	handler.fixAPIDescriber()
 	handler.fixIdentifierDesciber()
+	handler.Policies = handler.getPolicies()
+	handler.response = handler.getResponse()
+	handler.files = handler.getFiles()
+	handler.Timeout = handler.getTimeout()
+	handler.File, handler.Line = runtimeFunc(action).FileLine(runtimeFunc(action).Entry())
+
+	return Action{route: &routeDescriber{
+		apiDescriber:        handler.apiDescriber,
+		identifierDescriber: handler.identifierDescriber,
+		Decorators:          handler.getDecorators(),
+		GroupPath:           handler.getGroup(),
+		In:                  reflect.TypeOf(action).In(2),
+		Out:                 reflect.TypeOf(action).Out(0),
+		streamed:            handler.files.streamed(),
+		errs:                handler.errs,
+		handle: func(s *Server) app.HandlerFunc {
+			return register(s, handler)
+		},
+	}}
+}
 
-	key := fmt.Sprintf("%s::%s::%d::%s", handler.Verb, handler.Path, handler.Status, handler.ResponderType)
-
-	if handler.identifierDescriber != nil {
-		handlersMap[key] = append(handlersMap[key], identify(handler))
+// Register registers the actions in the root router or in the group of their `@group /prefix` directive.
+func (s *Server) Register(actions ...Action) {
+	for _, action := range actions {
+		s.add(action.route, nil)
 	}
+}
+
+func (s *Server) add(route *routeDescriber, group *RouterGroup) {
+	r := *route
+	r.server = s
+	r.group = group
+	// the action can be registered in several groups, each route having its own errors.
+	r.errs = slices.Clone(route.errs)
 
-	decorators := handler.getDecorators()
-	for _, dec := range decorators {
-		handlersMap[key] = append(handlersMap[key], decorate(handler.Path, handler.Verb, dec))
+	// the params of the routes in a group created afterwards are checked by Validate.
+	if r.described() && r.hasGroup() {
+		r.checkPathParams()
 	}
 
-	handlersMap[key] = append(handlersMap[key], register(handler))
+	s.routes = append(s.routes, &r)
 }
 
-func register[IN any, OUT any](handler *Handler[IN, OUT]) app.HandlerFunc {
+func register[IN any, OUT any](s *Server, handler *Handler[IN, OUT]) app.HandlerFunc {
+	files := handler.files
+
 	return func(c context.Context, r *app.RequestContext) {
-		reqType, err := bind(handler, r)
+		reqType, err := bind(handler, r, files.streamed())
 		if err != nil {
+			err = newBindError(r, reflect.TypeOf(handler.HandlerFn).In(2), err)
 			_ = r.Error(r.AbortWithError(http.StatusUnprocessableEntity, err))
+			s.fail(c, r, err)
+
+			return
+		}
+
+		if err := bindFiles(r, files, reqType); err != nil {
+			s.fail(c, r, err)
 
 			return
 		}
@@ -44,7 +111,7 @@ func register[IN any, OUT any](handler *Handler[IN, OUT]) app.HandlerFunc {
 			_, ok := err.(validator.ValidationErrors)
 			if ok || err.(*validator.InvalidValidationError).Type != nil {
 				_ = r.Error(r.AbortWithError(http.StatusBadRequest, err))
-				handleError(c, r, err)
+				s.fail(c, r, err)
 
 				return
 			}
@@ -52,26 +119,66 @@ func register[IN any, OUT any](handler *Handler[IN, OUT]) app.HandlerFunc {
 
 		req := &Request{r}
 
-		res, err := handler.HandlerFn(c, req, reqType)
-		if err != nil {
-			if handleError != nil {
-				handleError(c, r, err)
+		if err := s.authorizeInput(c, req, handler.Policies, reqType); err != nil {
+			s.fail(c, r, err)
 
-				return
-			}
+			return
+		}
+
+		// the context is cancelled once the response is written, so goroutines
+		// started by the handler, such as sse producers, stop with the request.
+		c, cancel := context.WithCancel(c)
+		defer cancel()
 
-			r.AbortWithError(500, err) //nolint
+		var res OUT
+
+		if timeout := handler.timeout(s); timeout > 0 {
+			c, cancel := context.WithTimeout(c, timeout)
+			defer cancel()
+
+			res, err = callWithTimeout(c, r, handler, reqType)
+		} else {
+			res, err = handler.HandlerFn(c, req, reqType)
+		}
+
+		// the client of a cancelled request, as a disconnected one, is not answered.
+		if errors.Is(err, context.Canceled) && c.Err() != nil {
+			r.AbortWithStatus(statusClientClosedRequest)
+
+			return
+		}
+
+		if err != nil {
+			s.fail(c, r, err)
 
 			return
 		}
 
-		handler.RespondFn(r, res)
+		var body any = res
+
+		if handler.response != nil {
+			if body, err = handler.response.apply(r, res); err != nil {
+				s.fail(c, r, err)
+
+				return
+			}
+		}
+
+		if err := handler.RespondFn(c, r, body); err != nil {
+			s.fail(c, r, err)
+		}
 	}
 }
 
 type Handler[IN any, OUT any] struct {
 	HandlerFn func(context.Context, *Request, IN) (OUT, error)
-	RespondFn func(rctx *app.RequestContext, response any)
+	RespondFn func(c context.Context, rctx *app.RequestContext, response any) error
+
+	// response holds the status, header and cookie fields of OUT.
+	response *responseMeta
+	files    fileFields
+	// errs are the problems of the describer and directives, reported by Validate.
+	errs []error
 
 	*apiDescriber
 	*identifierDescriber
@@ -84,7 +191,9 @@ func (h *Handler[IN, OUT]) fixAPIDescriber() {
 	apiDescriber := h.getFixedAPIDescriberFields(functionName, comments)
 
 	if len(apiDescriber) == 0 {
-		panic(functionName + " has not describer")
+		h.invalid("it has no describer, run go generate with cmd/hertzgen if its source is not available")
+
+		return
 	}
 
 	for _, d := range apiDescriber {
@@ -128,11 +237,19 @@ func (h *Handler[IN, OUT]) getFixedAPIDescriberFields(functionName string, comme
 	h.apiDescriber = new(apiDescriber)
 	h.FunctionName = functionName
 
+	var text []string
+
 	for _, describer := range comments {
 		if !strings.HasPrefix(describer, "[") {
+			if !strings.HasPrefix(describer, "@") {
+				text = append(text, describer)
+			}
+
 			continue
 		}
 
+		h.setSummary(text)
+
 		desc := strings.Split(describer, " ")
 
 		return desc
@@ -141,6 +258,15 @@ func (h *Handler[IN, OUT]) getFixedAPIDescriberFields(functionName string, comme
 	return []string{}
 }
 
+// setSummary sets the first line of the free text above the describer as summary
+// and the rest of it as description.
+func (h *Handler[IN, OUT]) setSummary(text []string) {
+	summary, description, _ := strings.Cut(strings.TrimSpace(strings.Join(text, "\n")), "\n")
+
+	h.Summary = strings.TrimSpace(summary)
+	h.Description = strings.TrimSpace(description)
+}
+
 func (h *Handler[IN, OUT]) fixIdentifierDesciber() {
 	comment := funcDescription(h.HandlerFn)
 	comments := strings.Split(comment, "\n")
@@ -150,22 +276,19 @@ func (h *Handler[IN, OUT]) fixIdentifierDesciber() {
 			continue
 		}
 
-		h.identifierDescriber = new(identifierDescriber)
-
 		describer, _ = strings.CutPrefix(describer, "@authorize")
-		describer = strings.ReplaceAll(describer, " ", "")
+		describer = strings.TrimSpace(describer)
 		describer, _ = strings.CutPrefix(describer, "(")
 		describer, _ = strings.CutSuffix(describer, ")")
 
-		before, after, _ := strings.Cut(describer, ":::")
+		identifierDescriber, err := parseAuthorize(describer)
+		if err != nil {
+			h.invalid("%v", err)
 
-		if len(before) > 0 {
-			h.identifierDescriber.Roles = strings.Split(before, ",") //nolint
+			continue
 		}
 
-		if len(after) > 0 {
-			h.identifierDescriber.Permissions = strings.Split(after, ",") //nolint
-		}
+		h.identifierDescriber = identifierDescriber
 	}
 }
 
@@ -175,11 +298,19 @@ func (h *Handler[IN, OUT]) getDecorators() (decorators []string) {
 
 	for _, describer := range comments {
 		if !strings.HasPrefix(describer, "@") ||
-			strings.HasPrefix(describer, "@authorize") {
+			strings.HasPrefix(describer, "@authorize") ||
+			strings.HasPrefix(describer, "@group") ||
+			strings.HasPrefix(describer, "@policy") ||
+			strings.HasPrefix(describer, "@timeout") {
 			continue
 		}
 
 		decorator, _ := strings.CutPrefix(describer, "@")
+		if _, _, err := parseDecorator(decorator); err != nil {
+			h.invalid("%v", err)
+
+			continue
+		}
 
 		decorators = append(decorators, decorator)
 	}
@@ -187,7 +318,82 @@ func (h *Handler[IN, OUT]) getDecorators() (decorators []string) {
 	return
 }
 
-func bind[IN any, OUT any](handler *Handler[IN, OUT], rctx *app.RequestContext) (req IN, err error) {
+// getFiles returns the file and parts fields of the input.
+func (h *Handler[IN, OUT]) getFiles() fileFields {
+	files, err := fileFieldsOf(reflect.TypeOf(h.HandlerFn).In(2))
+	if err != nil {
+		h.invalid("%v", err)
+	}
+
+	return files
+}
+
+func (h *Handler[IN, OUT]) getResponse() *responseMeta {
+	// the other responders get OUT itself, whose methods they call, such as String or Render.
+	meta, err := responseMetaOf(reflect.TypeOf(h.HandlerFn).Out(0), h.encodes())
+	if err != nil {
+		h.invalid("%v", err)
+	}
+
+	return meta
+}
+
+// encodes reports whether the responder encodes the fields of OUT as the body.
+func (h *Handler[IN, OUT]) encodes() bool {
+	if _, ok := negotiateEncoders(h.ResponderType); ok {
+		return true
+	}
+
+	switch h.ResponderType {
+	case "json", "json_pure", "xml":
+		return true
+	}
+
+	return false
+}
+
+// invalid records a problem of the describer or directives of the handler.
+func (h *Handler[IN, OUT]) invalid(format string, args ...any) {
+	h.errs = append(h.errs, fmt.Errorf(format, args...))
+}
+
+func (h *Handler[IN, OUT]) getGroup() string {
+	comment := funcDescription(h.HandlerFn)
+	comments := strings.Split(comment, "\n")
+
+	for _, describer := range comments {
+		if group, ok := strings.CutPrefix(describer, "@group "); ok {
+			return strings.TrimRight(strings.TrimSpace(group), "/")
+		}
+	}
+
+	return ""
+}
+
+// getPolicies returns the policies of the `@policy a, b` directives.
+func (h *Handler[IN, OUT]) getPolicies() (policies []string) {
+	comment := funcDescription(h.HandlerFn)
+	comments := strings.Split(comment, "\n")
+
+	for _, describer := range comments {
+		names, ok := strings.CutPrefix(describer, "@policy ")
+		if !ok {
+			continue
+		}
+
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				policies = append(policies, name)
+			}
+		}
+	}
+
+	return
+}
+
+// bind binds the request to the input of the handler. The body of a streamed input,
+// which has a Parts field, is left to it.
+func bind[IN any, OUT any](handler *Handler[IN, OUT], rctx *app.RequestContext, streamed bool) (req IN, err error) {
 	p := reflect.TypeOf(handler.HandlerFn).In(2)
 	if p.Kind() == reflect.Interface {
 		return
@@ -195,12 +401,40 @@ func bind[IN any, OUT any](handler *Handler[IN, OUT], rctx *app.RequestContext)
 
 	req = reflect.New(p.Elem()).Interface().(IN)
 
-	err = rctx.Bind(req)
+	if !streamed {
+		err = rctx.Bind(req)
+
+		return
+	}
+
+	// the binder reads the whole body, which the Parts of the input stream.
+	for _, bind := range []func(any) error{rctx.BindPath, rctx.BindQuery, rctx.BindHeader} {
+		if err = bind(req); err != nil {
+			return
+		}
+	}
 
 	return
 }
 
-var validate = validator.New()
+var validate = newValidator()
+
+// newValidator creates a validator reporting fields by the names they are bound from.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "query", "path", "header"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+
+		return field.Name
+	})
+
+	return v
+}
 
 type apiDescriber struct {
 	FunctionName  string
@@ -209,11 +443,95 @@ type apiDescriber struct {
 	Status        int
 	ContentType   string
 	ResponderType string
+	Summary       string
+	Description   string
+	Policies      []string
+	Timeout       time.Duration
+	// File and Line are the source of the handler.
+	File string
+	Line int
+}
+
+type routeDescriber struct {
+	*apiDescriber
+	*identifierDescriber
+	Decorators []string
+	GroupPath  string
+	In         reflect.Type
+	Out        reflect.Type
+	// streamed reports whether the IN struct has a Parts field, which reads the body.
+	streamed bool
+
+	errs []error
+	// unusedPathTags are the `path` tags which are not params of the path, reported
+	// by Validate in strict mode. pathChecked reports they and the missing params,
+	// added to errs, were checked.
+	unusedPathTags []error
+	pathChecked    bool
+	// decorated are the decorators created for the directives, so Validate and Build
+	// call the factories once.
+	decorated map[string]decoratorFn
+
+	server *Server
+	group  *RouterGroup
+	handle func(*Server) app.HandlerFunc
+}
+
+func (r *routeDescriber) routerGroup() *RouterGroup {
+	if r.group != nil {
+		return r.group
+	}
+
+	if r.GroupPath == "" {
+		return r.server.root
+	}
+
+	group, ok := r.server.groups[r.GroupPath]
+	if !ok {
+		panic(fmt.Sprintf("group %s of %s does not exist", r.GroupPath, r.FunctionName))
+	}
+
+	return group
+}
+
+// FullPath returns the path of the route prefixed by the base path of its group.
+func (r *routeDescriber) FullPath() string {
+	return r.routerGroup().BasePath() + r.Path
+}
+
+// authorization returns the @authorize describer of the route or the default of its group.
+func (r *routeDescriber) authorization() *identifierDescriber {
+	if r.identifierDescriber != nil {
+		return r.identifierDescriber
+	}
+
+	return r.routerGroup().defaultAuthorize()
+}
+
+func (r *routeDescriber) handlers() []app.HandlerFunc {
+	handlers := make([]app.HandlerFunc, 0)
+
+	if r.streamed {
+		handlers = append(handlers, markStreamed)
+	}
+
+	if describer := r.authorization(); describer != nil {
+		handlers = append(handlers, r.server.identify(describer))
+	}
+
+	for _, dec := range slices.Concat(r.routerGroup().allDecorators(), r.Decorators) {
+		handlers = append(handlers, r.decorate(dec))
+	}
+
+	return append(handlers, r.handle(r.server))
 }
 
 type identifierDescriber struct {
+	// Roles and Permissions are the lists of the `@authorize(roles ::: permissions)`
+	// syntax passed to the identifier, and are empty for expressions.
 	Roles       []string
 	Permissions []string
+	Expr        authExpr
 }
 
 type ErrorHandler func(c context.Context, rctx *app.RequestContext, err error)
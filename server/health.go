@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+const (
+	healthPath = "/healthz"
+	readyPath  = "/readyz"
+	livePath   = "/livez"
+
+	defaultHealthCheckTimeout = 2 * time.Second
+)
+
+// HealthCheckOptions are the options of a health check.
+type HealthCheckOptions struct {
+	// Timeout of the check, 2s by default. A check which does not return in time fails.
+	Timeout time.Duration
+	// Optional checks do not fail the probes, which are then degraded but still 200.
+	Optional bool
+	// CacheTTL is how long the result of the check is reused, none by default.
+	CacheTTL time.Duration
+	// Liveness checks also run for /livez, which only fails if one of them fails.
+	Liveness bool
+}
+
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+	opts  HealthCheckOptions
+
+	// mu serializes the runs of the check, so the concurrent probes share its cached result.
+	mu        sync.Mutex
+	result    healthCheckResult
+	checkedAt time.Time
+}
+
+// health holds the health checks of a Server and whether it serves the probes.
+type health struct {
+	serve      bool
+	drainDelay time.Duration
+	// draining reports a graceful shutdown was signaled, which waits for the drain delay.
+	draining atomic.Bool
+	mu       sync.RWMutex
+	checks   []*healthCheck
+}
+
+type healthReport struct {
+	Status string                       `json:"status"`
+	Checks map[string]healthCheckResult `json:"checks,omitempty"`
+}
+
+type healthCheckResult struct {
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+	healthFailing  = "failing"
+)
+
+// AddHealthCheck adds a check run by the probes served with WithHealth. A check with
+// the name of another replaces it.
+//
+//	s.AddHealthCheck("postgres", db.PingContext, server.HealthCheckOptions{
+//		Timeout:  time.Second,
+//		CacheTTL: 5 * time.Second,
+//	})
+func (s *Server) AddHealthCheck(name string, check func(ctx context.Context) error, opts HealthCheckOptions) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultHealthCheckTimeout
+	}
+
+	hc := &healthCheck{name: name, check: check, opts: opts}
+
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+
+	for i, other := range s.health.checks {
+		if other.name == name {
+			s.health.checks[i] = hc
+
+			return
+		}
+	}
+
+	s.health.checks = append(s.health.checks, hc)
+}
+
+// AddHealthCheck adds a check run by the probes of the default Server.
+func AddHealthCheck(name string, check func(ctx context.Context) error, opts HealthCheckOptions) {
+	defaultServer.AddHealthCheck(name, check, opts)
+}
+
+func (s *Server) healthHandlers() map[string]app.HandlerFunc {
+	if !s.health.serve {
+		return nil
+	}
+
+	return map[string]app.HandlerFunc{
+		healthPath: s.probe(false, false),
+		readyPath:  s.probe(false, true),
+		livePath:   s.probe(true, false),
+	}
+}
+
+// probe returns the handler of a probe running the liveness or all the checks.
+// A readiness probe fails once a graceful shutdown is signaled, or once the server
+// shuts down if it is not signaled, as by a direct call of Shutdown.
+func (s *Server) probe(liveness, readiness bool) app.HandlerFunc {
+	return func(c context.Context, rctx *app.RequestContext) {
+		report := s.health.report(c, liveness)
+
+		if readiness && (s.health.draining.Load() || s.lifecycle.stopping.Load()) {
+			report.Status = healthFailing
+		}
+
+		status := http.StatusOK
+		if report.Status == healthFailing {
+			status = http.StatusServiceUnavailable
+		}
+
+		rctx.Response.Header.Set("Cache-Control", "no-store")
+		rctx.JSON(status, report)
+	}
+}
+
+// waitSignal is the signal waiter of a hertz server serving the probes. It waits for
+// the signals as hertz does, then fails /readyz for the drain delay before the graceful
+// shutdown, while the server still serves the requests routed to it in the meantime.
+// SIGTERM closes the server at once, as in hertz.
+func (s *Server) waitSignal(errCh chan error) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM)
+
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-errCh:
+		return err
+	case sig := <-signals:
+		if sig == syscall.SIGTERM {
+			return errors.New(sig.String())
+		}
+
+		hlog.SystemLogger().Infof("Received signal: %s, draining for %s", sig, s.health.drainDelay)
+	}
+
+	s.health.draining.Store(true)
+
+	timer := time.NewTimer(s.health.drainDelay)
+	defer timer.Stop()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-signals:
+		// a second signal skips the rest of the drain delay.
+	case <-timer.C:
+	}
+
+	return nil
+}
+
+// report runs the checks concurrently.
+func (h *health) report(c context.Context, liveness bool) healthReport {
+	h.mu.RLock()
+	checks := make([]*healthCheck, 0, len(h.checks))
+
+	for _, check := range h.checks {
+		if !liveness || check.opts.Liveness {
+			checks = append(checks, check)
+		}
+	}
+	h.mu.RUnlock()
+
+	results := make([]healthCheckResult, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i, check := range checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i] = check.run(c)
+		}()
+	}
+
+	wg.Wait()
+
+	report := healthReport{Status: healthOK, Checks: make(map[string]healthCheckResult, len(checks))}
+
+	for i, result := range results {
+		report.Checks[checks[i].name] = result
+
+		switch {
+		case result.Status == healthOK:
+		case result.Critical:
+			report.Status = healthFailing
+		case report.Status == healthOK:
+			report.Status = healthDegraded
+		}
+	}
+
+	return report
+}
+
+// run runs the check, or returns its result while it is cached.
+func (hc *healthCheck) run(c context.Context) healthCheckResult {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	if hc.opts.CacheTTL > 0 && time.Since(hc.checkedAt) < hc.opts.CacheTTL {
+		return hc.result
+	}
+
+	// the result is shared with the other probes, so it does not depend on this one.
+	start := time.Now()
+	err := callTimeout(context.WithoutCancel(c), hc.opts.Timeout, hc.check)
+
+	hc.checkedAt = start
+	hc.result = healthCheckResult{
+		Status:    healthOK,
+		Critical:  !hc.opts.Optional,
+		Duration:  time.Since(start).String(),
+		CheckedAt: start,
+	}
+
+	if err != nil {
+		hc.result.Status = healthFailing
+		hc.result.Error = err.Error()
+	}
+
+	return hc.result
+}
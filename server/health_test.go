@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func healthy(context.Context) error { return nil }
+
+func unhealthy(context.Context) error { return errors.New("connection refused") }
+
+// probeHealth returns the status and report of a probe.
+func probeHealth(t *testing.T, engine *route.Engine, path string) (int, healthReport) {
+	t.Helper()
+
+	res := ut.PerformRequest(engine, http.MethodGet, path, nil)
+
+	var report healthReport
+	if err := json.Unmarshal(res.Body.Bytes(), &report); err != nil {
+		t.Fatalf("%s: %v: %s", path, err, res.Body.String())
+	}
+
+	return res.Code, report
+}
+
+func TestHealth(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(s *Server)
+		path   string
+		status int
+		report string
+	}{
+		{
+			name: "healthy",
+			setup: func(s *Server) {
+				s.AddHealthCheck("db", healthy, HealthCheckOptions{})
+			},
+			path: healthPath, status: http.StatusOK, report: healthOK,
+		},
+		{
+			name: "optional failing",
+			setup: func(s *Server) {
+				s.AddHealthCheck("db", healthy, HealthCheckOptions{})
+				s.AddHealthCheck("cache", unhealthy, HealthCheckOptions{Optional: true})
+			},
+			path: healthPath, status: http.StatusOK, report: healthDegraded,
+		},
+		{
+			name: "critical failing",
+			setup: func(s *Server) {
+				s.AddHealthCheck("db", unhealthy, HealthCheckOptions{})
+				s.AddHealthCheck("cache", unhealthy, HealthCheckOptions{Optional: true})
+			},
+			path: readyPath, status: http.StatusServiceUnavailable, report: healthFailing,
+		},
+		{
+			name: "critical timed out",
+			setup: func(s *Server) {
+				s.AddHealthCheck("db", func(context.Context) error {
+					time.Sleep(time.Second)
+
+					return nil
+				}, HealthCheckOptions{Timeout: 10 * time.Millisecond})
+			},
+			path: healthPath, status: http.StatusServiceUnavailable, report: healthFailing,
+		},
+		{
+			name: "liveness",
+			setup: func(s *Server) {
+				s.AddHealthCheck("db", unhealthy, HealthCheckOptions{})
+				s.AddHealthCheck("loop", healthy, HealthCheckOptions{Liveness: true})
+			},
+			path: livePath, status: http.StatusOK, report: healthOK,
+		},
+		{
+			name: "replaced",
+			setup: func(s *Server) {
+				s.AddHealthCheck("db", unhealthy, HealthCheckOptions{})
+				s.AddHealthCheck("db", healthy, HealthCheckOptions{})
+			},
+			path: healthPath, status: http.StatusOK, report: healthOK,
+		},
+	}
+
+	for _, tt := range tests {
+		s := New(WithHealth(0))
+		tt.setup(s)
+
+		status, report := probeHealth(t, s.Build().Engine, tt.path)
+		if status != tt.status || report.Status != tt.report {
+			t.Errorf("%s: %s = %d %s, want %d %s", tt.name, tt.path, status, report.Status, tt.status, tt.report)
+		}
+	}
+}
+
+func TestHealthReport(t *testing.T) {
+	s := New(WithHealth(0))
+	s.AddHealthCheck("db", unhealthy, HealthCheckOptions{})
+	s.AddHealthCheck("cache", healthy, HealthCheckOptions{Optional: true})
+	s.AddHealthCheck("slow", func(context.Context) error {
+		time.Sleep(time.Second)
+
+		return nil
+	}, HealthCheckOptions{Timeout: 10 * time.Millisecond, Optional: true})
+
+	_, report := probeHealth(t, s.Build().Engine, healthPath)
+
+	if db := report.Checks["db"]; db.Status != healthFailing || !db.Critical || db.Error != "connection refused" {
+		t.Errorf("db = %+v", db)
+	}
+
+	if cache := report.Checks["cache"]; cache.Status != healthOK || cache.Critical || cache.Error != "" {
+		t.Errorf("cache = %+v", cache)
+	}
+
+	if slow := report.Checks["slow"]; slow.Status != healthFailing || !strings.Contains(slow.Error, "did not return in time") {
+		t.Errorf("slow = %+v", slow)
+	}
+}
+
+func TestHealthCache(t *testing.T) {
+	s := New(WithHealth(0))
+
+	var cached, uncached atomic.Int32
+
+	s.AddHealthCheck("cached", func(context.Context) error {
+		cached.Add(1)
+
+		return nil
+	}, HealthCheckOptions{CacheTTL: time.Hour})
+	s.AddHealthCheck("uncached", func(context.Context) error {
+		uncached.Add(1)
+
+		return nil
+	}, HealthCheckOptions{})
+
+	engine := s.Build().Engine
+
+	probeHealth(t, engine, healthPath)
+	_, report := probeHealth(t, engine, readyPath)
+
+	if cached.Load() != 1 || uncached.Load() != 2 {
+		t.Errorf("checks run %d and %d times, want the cached one once", cached.Load(), uncached.Load())
+	}
+
+	if report.Checks["cached"].CheckedAt.After(report.Checks["uncached"].CheckedAt) {
+		t.Errorf("cached check at %v, want the time of its first run", report.Checks["cached"].CheckedAt)
+	}
+}
+
+func TestReadinessShutdown(t *testing.T) {
+	for _, stop := range []func(s *Server){
+		func(s *Server) { s.health.draining.Store(true) },
+		func(s *Server) { s.lifecycle.stopping.Store(true) },
+	} {
+		s := New(WithHealth(time.Second))
+		s.AddHealthCheck("db", healthy, HealthCheckOptions{})
+
+		engine := s.Build().Engine
+		if status, _ := probeHealth(t, engine, readyPath); status != http.StatusOK {
+			t.Fatalf("ready = %d before the shutdown", status)
+		}
+
+		stop(s)
+
+		if status, report := probeHealth(t, engine, readyPath); status != http.StatusServiceUnavailable || report.Status != healthFailing {
+			t.Errorf("ready = %d %s, want failing once the shutdown starts", status, report.Status)
+		}
+
+		if status, _ := probeHealth(t, engine, healthPath); status != http.StatusOK {
+			t.Errorf("health = %d, want the server still healthy", status)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/maadiii/hertz/server/internal/resp/resptest"
+)
+
+var (
+	payments       atomic.Int32
+	paying         atomic.Int32
+	concurrentPays atomic.Bool
+	unblockPayment = make(chan struct{})
+)
+
+// slowPayment pays until unblockPayment is closed, past its timeout.
+//
+// @authorize
+// @idempotent
+// @timeout(20ms)
+// [POST] /payments 201 json
+func slowPayment(_ context.Context, _ *Request, _ *struct{}) (map[string]int32, error) {
+	if paying.Add(1) > 1 {
+		concurrentPays.Store(true)
+	}
+	defer paying.Add(-1)
+
+	<-unblockPayment
+
+	return map[string]int32{"payment": payments.Add(1)}, nil
+}
+
+func identifyAs(id string) identifierFn {
+	return func(_ context.Context, req *Request, _ []string, _ ...string) {
+		req.SetIdentity(Identity{ID: id})
+	}
+}
+
+func TestIdempotentTimeoutRetry(t *testing.T) {
+	s := New()
+	s.SetIdentifier(identifyAs("1"))
+	s.Register(NewAction(slowPayment))
+
+	engine := s.Build().Engine
+	pay := func() int {
+		return ut.PerformRequest(engine, http.MethodPost, "/payments", nil, ut.Header{Key: "Idempotency-Key", Value: "k1"}).Code
+	}
+
+	if status := pay(); status != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want the 503 of the timeout", status)
+	}
+
+	// the abandoned handler still runs, so its key is still in flight.
+	if status := pay(); status != http.StatusConflict {
+		t.Fatalf("retry while the abandoned handler runs = %d, want 409", status)
+	}
+
+	close(unblockPayment)
+
+	status := pay()
+	for deadline := time.Now().Add(time.Second); status == http.StatusConflict && time.Now().Before(deadline); status = pay() {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if status != http.StatusCreated {
+		t.Fatalf("retry once the abandoned handler returned = %d, want 201", status)
+	}
+
+	if concurrentPays.Load() {
+		t.Error("a retry ran concurrently with the abandoned handler")
+	}
+}
+
+var (
+	orders    atomic.Int32
+	orderGate chan struct{}
+)
+
+// createOrder counts the orders, waiting for orderGate if it is set.
+//
+// @idempotent
+// [POST] /orders 201 json
+func createOrder(_ context.Context, _ *Request, in *struct {
+	Item string `json:"item"`
+}) (map[string]any, error) {
+	if orderGate != nil {
+		<-orderGate
+	}
+
+	return map[string]any{"order": orders.Add(1), "item": in.Item}, nil
+}
+
+// createOptionalOrder does not require an Idempotency-Key.
+//
+// @idempotent(1h, optional)
+// [POST] /optional 201 json
+func createOptionalOrder(_ context.Context, _ *Request, _ *struct{}) (map[string]int32, error) {
+	return map[string]int32{"order": orders.Add(1)}, nil
+}
+
+// createUserOrder scopes its keys by the identity of the request.
+//
+// @authorize
+// @idempotent
+// [POST] /user-orders 201 json
+func createUserOrder(_ context.Context, _ *Request, _ *struct{}) (map[string]int32, error) {
+	return map[string]int32{"order": orders.Add(1)}, nil
+}
+
+func postOrder(engine *route.Engine, path, key, body string, headers ...ut.Header) *ut.ResponseRecorder {
+	headers = append(headers, ut.Header{Key: "Content-Type", Value: "application/json"})
+	if key != "" {
+		headers = append(headers, ut.Header{Key: "Idempotency-Key", Value: key})
+	}
+
+	return ut.PerformRequest(engine, http.MethodPost, path, &ut.Body{Body: strings.NewReader(body), Len: len(body)}, headers...)
+}
+
+func TestIdempotent(t *testing.T) {
+	s := New()
+	s.SetIdentifier(func(_ context.Context, req *Request, _ []string, _ ...string) {
+		if user := string(req.GetHeader("X-User")); user != "" {
+			req.SetIdentity(Identity{ID: user})
+		}
+	})
+	s.Register(NewAction(createOrder), NewAction(createOptionalOrder), NewAction(createUserOrder))
+
+	engine := s.Build().Engine
+
+	first := postOrder(engine, "/orders", "k1", `{"item":"pen"}`)
+	if first.Code != http.StatusCreated || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first request = %d %s", first.Code, first.Body.String())
+	}
+
+	replayed := postOrder(engine, "/orders", "k1", `{"item":"pen"}`)
+	if replayed.Code != http.StatusCreated || replayed.Header().Get("Idempotent-Replayed") != "true" || replayed.Body.String() != first.Body.String() {
+		t.Errorf("retry = %d %q %s, want the replayed %s", replayed.Code, replayed.Header().Get("Idempotent-Replayed"), replayed.Body.String(), first.Body.String())
+	}
+
+	if res := postOrder(engine, "/orders", "k1", `{"item":"ink"}`); res.Code != http.StatusUnprocessableEntity {
+		t.Errorf("key reused with another body = %d, want 422", res.Code)
+	}
+
+	if res := postOrder(engine, "/orders", "", `{"item":"pen"}`); res.Code != http.StatusBadRequest {
+		t.Errorf("request without a key = %d, want 400", res.Code)
+	}
+
+	before := orders.Load()
+
+	for range 2 {
+		if res := postOrder(engine, "/optional", "", `{}`); res.Code != http.StatusCreated {
+			t.Errorf("optional request without a key = %d, want 201", res.Code)
+		}
+	}
+
+	if got := orders.Load() - before; got != 2 {
+		t.Errorf("optional requests without a key ran %d times, want 2", got)
+	}
+
+	// the keys of identities are apart, and apart from the anonymous ones.
+	before = orders.Load()
+
+	for _, user := range []string{"alice", "bob", ""} {
+		for range 2 {
+			if res := postOrder(engine, "/user-orders", "k1", `{}`, ut.Header{Key: "X-User", Value: user}); res.Code != http.StatusCreated {
+				t.Errorf("order of %q = %d, want 201", user, res.Code)
+			}
+		}
+	}
+
+	if got := orders.Load() - before; got != 3 {
+		t.Errorf("the orders of 3 scopes ran %d times, want 3", got)
+	}
+}
+
+func TestIdempotentInFlight(t *testing.T) {
+	s := New()
+	s.Register(NewAction(createOrder))
+
+	engine := s.Build().Engine
+	orderGate = make(chan struct{})
+
+	defer func() { orderGate = nil }()
+
+	done := make(chan int)
+
+	go func() {
+		done <- postOrder(engine, "/orders", "in-flight", `{"item":"pen"}`).Code
+	}()
+
+	status := http.StatusCreated
+	for deadline := time.Now().Add(time.Second); status != http.StatusConflict && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		status = postOrder(engine, "/orders", "in-flight", `{"item":"pen"}`).Code
+	}
+
+	if status != http.StatusConflict {
+		t.Errorf("retry in flight = %d, want 409", status)
+	}
+
+	close(orderGate)
+
+	if status := <-done; status != http.StatusCreated {
+		t.Errorf("first request = %d, want 201", status)
+	}
+}
+
+func TestIdempotencyStores(t *testing.T) {
+	file, err := NewFileIdempotencyStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := resptest.NewServer(newRedisStandIn())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	redis, err := NewRedisIdempotencyStore(srv.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.Close()
+
+	stores := map[string]IdempotencyStore{
+		"memory": NewMemoryIdempotencyStore(),
+		"file":   file,
+		"redis":  redis,
+	}
+
+	for name, store := range stores {
+		c := context.Background()
+		record := &IdempotencyRecord{Fingerprint: "f"}
+
+		if _, ok, err := store.Begin(c, "k", record, time.Minute); err != nil || !ok {
+			t.Fatalf("%s: Begin = %t, %v, want the key reserved", name, ok, err)
+		}
+
+		existing, ok, err := store.Begin(c, "k", record, time.Minute)
+		if err != nil || ok || existing == nil || existing.Done || existing.Fingerprint != "f" {
+			t.Errorf("%s: Begin of a reserved key = %+v, %t, %v, want the in-flight record", name, existing, ok, err)
+		}
+
+		done := &IdempotencyRecord{Fingerprint: "f", Done: true, Status: 201, Header: [][2]string{{"Location", "/orders/1"}}, Body: []byte("{}")}
+		if err := store.Complete(c, "k", done, time.Minute); err != nil {
+			t.Fatalf("%s: Complete: %v", name, err)
+		}
+
+		existing, ok, err = store.Begin(c, "k", record, time.Minute)
+		if err != nil || ok || !reflect.DeepEqual(existing, done) {
+			t.Errorf("%s: Begin of a done key = %+v, %t, %v, want %+v", name, existing, ok, err, done)
+		}
+
+		if err := store.Release(c, "k"); err != nil {
+			t.Fatalf("%s: Release: %v", name, err)
+		}
+
+		if _, ok, err := store.Begin(c, "k", record, time.Minute); err != nil || !ok {
+			t.Errorf("%s: Begin of a released key = %t, %v, want the key reserved", name, ok, err)
+		}
+
+		// an expired key is reserved again.
+		if _, ok, err := store.Begin(c, "expiring", record, time.Millisecond); err != nil || !ok {
+			t.Fatalf("%s: Begin = %t, %v", name, ok, err)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+
+		if _, ok, err := store.Begin(c, "expiring", record, time.Minute); err != nil || !ok {
+			t.Errorf("%s: Begin of an expired key = %t, %v, want the key reserved", name, ok, err)
+		}
+	}
+}
+
+// newRedisStandIn answers the SET, GET and DEL commands of the Redis store.
+func newRedisStandIn() resptest.Handler {
+	type entry struct {
+		value   string
+		expires time.Time
+	}
+
+	entries := make(map[string]entry)
+
+	lookup := func(key string) (entry, bool) {
+		e, ok := entries[key]
+		if ok && !time.Now().Before(e.expires) {
+			delete(entries, key)
+
+			return entry{}, false
+		}
+
+		return e, ok
+	}
+
+	return func(args []string) any {
+		switch strings.ToUpper(args[0]) {
+		case "SET":
+			e := entry{value: args[2]}
+			nx := false
+
+			for i := 3; i < len(args); i++ {
+				switch strings.ToUpper(args[i]) {
+				case "NX":
+					nx = true
+				case "PX":
+					ms, _ := strconv.Atoi(args[i+1])
+					e.expires = time.Now().Add(time.Duration(ms) * time.Millisecond)
+					i++
+				}
+			}
+
+			if _, ok := lookup(args[1]); ok && nx {
+				return nil
+			}
+
+			entries[args[1]] = e
+
+			return resptest.Status("OK")
+		case "GET":
+			if e, ok := lookup(args[1]); ok {
+				return e.value
+			}
+
+			return nil
+		case "DEL":
+			if _, ok := lookup(args[1]); ok {
+				delete(entries, args[1])
+
+				return 1
+			}
+
+			return 0
+		}
+
+		return errors.New("ERR unknown command " + args[0])
+	}
+}
@@ -6,9 +6,22 @@ import (
 	"github.com/cloudwego/hertz/pkg/app"
 )
 
-// SetIdentifier set authentication and authorization method
+// SetIdentifier set authentication method. The identifier sets the identity of the request
+// by req.SetIdentity, and the identity is authorized by the @authorize expression of the route.
+// The identifier also gets the roles and permissions of the `@authorize(roles ::: permissions)` syntax.
+//
+// An identifier which does not set the identity nor abort the request authorizes the
+// routes of the list syntax or without arguments by itself, as before expressions were
+// supported. The routes of an expression reject such requests with 401, since the
+// expression cannot be evaluated without an identity.
+func (s *Server) SetIdentifier(identifierFn identifierFn) {
+	s.identifier = identifierFn
+}
+
+// SetIdentifier set authentication method. The identifier sets the identity of the request
+// by req.SetIdentity, and the identity is authorized by the @authorize expression of the route.
 func SetIdentifier(identifierFn identifierFn) {
-	identifier = identifierFn
+	defaultServer.SetIdentifier(identifierFn)
 }
 
 func (req *Request) SetIdentity(identity Identity) {
@@ -19,25 +32,63 @@ func (req *Request) Identity() Identity {
 	return req.rc.Value(identityKey).(Identity)
 }
 
+// LookupIdentity returns the identity of the request, if the identifier set one.
+func (req *Request) LookupIdentity() (Identity, bool) {
+	return IdentityOf(req.rc)
+}
+
+// IdentityOf returns the identity set on the request context by the identifier.
+func IdentityOf(rctx *app.RequestContext) (Identity, bool) {
+	identity, ok := rctx.Value(identityKey).(Identity)
+
+	return identity, ok
+}
+
 type (
 	identifierFn func(c context.Context, req *Request, roles []string, permissions ...string)
 )
 
-const identityKey = "identity"
-
-var identifier identifierFn
+const (
+	identityKey = "identity"
+	// anonymousKey marks the requests authorized by an identifier without an identity.
+	anonymousKey = "anonymous"
+)
 
-func identify[IN any, OUT any](handler *Handler[IN, OUT]) app.HandlerFunc {
+// identify authenticates the request by the identifier, then authorizes its identity
+// by the @authorize expression of the route.
+func (s *Server) identify(describer *identifierDescriber) app.HandlerFunc {
 	return func(c context.Context, rctx *app.RequestContext) {
 		req := &Request{rctx}
 
-		identifier(c, req, handler.Roles, handler.Permissions...)
+		s.identifier(c, req, describer.Roles, describer.Permissions...)
+		if rctx.IsAborted() {
+			return
+		}
+
+		identity, ok := IdentityOf(rctx)
+		if !ok {
+			if !describer.listed() {
+				req.Fail(c, Unauthorized("the request is not authenticated"))
+
+				return
+			}
+
+			rctx.Set(anonymousKey, true)
+
+			return
+		}
+
+		if !describer.Expr.eval(identity) {
+			req.Fail(c, Forbidden("the identity is not authorized"))
+		}
 	}
 }
 
 type Identity struct {
-	ID          string
-	Role        string
+	ID   string
+	Role string
+	// Roles are the other roles of the identity, if it has more than Role.
+	Roles       []string
 	Permissions []string
 	Data        map[string]any
 }
@@ -0,0 +1,269 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval limits the loads of a key set caused by unknown key ids.
+const minRefreshInterval = time.Minute
+
+// keySet holds the static keys and the cached keys of the JWKS sources.
+type keySet struct {
+	cfg    Config
+	static map[string]any
+
+	mu          sync.RWMutex
+	keys        map[string]any
+	loadedAt    time.Time
+	attemptedAt time.Time
+	loading     sync.Mutex
+}
+
+func newKeySet(cfg Config) (*keySet, error) {
+	if err := checkKeys(cfg.Keys); err != nil {
+		return nil, err
+	}
+
+	ks := &keySet{cfg: cfg, static: cfg.Keys}
+
+	if cfg.JWKSFile == "" && cfg.JWKSURL == "" {
+		return ks, nil
+	}
+
+	if err := ks.load(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// checkKeys checks the types of the static keys and the curve of the ES256 keys.
+func checkKeys(keys map[string]any) error {
+	for kid, key := range keys {
+		switch key := key.(type) {
+		case []byte:
+			if len(key) == 0 {
+				return fmt.Errorf("jwt: key %q is an empty secret", kid)
+			}
+		case *rsa.PublicKey:
+		case *ecdsa.PublicKey:
+			if key.Curve != elliptic.P256() {
+				return fmt.Errorf("jwt: key %q is not a P-256 key, as ES256 requires", kid)
+			}
+		default:
+			return fmt.Errorf("jwt: key %q has the unsupported type %T", kid, key)
+		}
+	}
+
+	return nil
+}
+
+// get returns the key of kid. The JWKS sources are loaded again once the refresh
+// interval passes or kid is unknown, and the cached keys are kept if loading fails.
+func (ks *keySet) get(c context.Context, kid string) (any, error) {
+	if key, ok := ks.static[kid]; ok {
+		return key, nil
+	}
+
+	if ks.cfg.JWKSFile == "" && ks.cfg.JWKSURL == "" {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	ks.mu.RLock()
+	key, ok := ks.keys[kid]
+	stale := time.Since(ks.loadedAt) >= ks.cfg.RefreshInterval
+	throttled := time.Since(ks.attemptedAt) < minRefreshInterval
+	ks.mu.RUnlock()
+
+	if (!ok || stale) && !throttled {
+		_ = ks.load(c)
+
+		ks.mu.RLock()
+		key, ok = ks.keys[kid]
+		ks.mu.RUnlock()
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	return key, nil
+}
+
+// load loads the JWKS sources, unless another load is attempted meanwhile.
+func (ks *keySet) load(c context.Context) error {
+	ks.mu.RLock()
+	attemptedAt := ks.attemptedAt
+	ks.mu.RUnlock()
+
+	ks.loading.Lock()
+	defer ks.loading.Unlock()
+
+	ks.mu.Lock()
+	if !ks.attemptedAt.Equal(attemptedAt) {
+		ks.mu.Unlock()
+
+		return nil
+	}
+
+	ks.attemptedAt = time.Now()
+	ks.mu.Unlock()
+
+	keys := make(map[string]any)
+
+	if ks.cfg.JWKSFile != "" {
+		data, err := os.ReadFile(ks.cfg.JWKSFile)
+		if err != nil {
+			return fmt.Errorf("jwt: read jwks: %w", err)
+		}
+
+		if err := parseJWKS(data, keys); err != nil {
+			return err
+		}
+	}
+
+	if ks.cfg.JWKSURL != "" {
+		data, err := ks.fetch(c)
+		if err != nil {
+			return err
+		}
+
+		if err := parseJWKS(data, keys); err != nil {
+			return err
+		}
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.loadedAt = time.Now()
+	ks.mu.Unlock()
+
+	return nil
+}
+
+func (ks *keySet) fetch(c context.Context) ([]byte, error) {
+	c, cancel := context.WithTimeout(context.WithoutCancel(c), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(c, http.MethodGet, ks.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: fetch jwks: %w", err)
+	}
+
+	res, err := ks.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: fetch jwks: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwt: fetch jwks: %s", res.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, 1<<20))
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS adds the verification keys of a JSON Web Key Set to keys. Malformed keys
+// and keys of other types, curves or uses are skipped, so a bad key does not disable
+// the others. Symmetric keys are skipped as well, since a key set is public.
+func parseJWKS(data []byte, keys map[string]any) error {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("jwt: parse jwks: %w", err)
+	}
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		if key, err := k.publicKey(); err == nil && key != nil {
+			keys[k.Kid] = key
+		}
+	}
+
+	return nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, nil
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !key.Curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+
+		return key, nil
+	}
+
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
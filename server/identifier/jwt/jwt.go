@@ -0,0 +1,177 @@
+// Package jwt is an identifier verifying bearer JSON Web Tokens.
+//
+//	identifier, err := jwt.New(jwt.Config{
+//		JWKSURL: "https://auth.example.com/.well-known/jwks.json",
+//		Issuer:  "https://auth.example.com/",
+//		Claims:  jwt.ClaimPaths{Role: "realm_access.role", Permissions: "scope"},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	server.SetIdentifier(identifier.Identify)
+//
+// Requests without a valid token are rejected with 401. The server rejects the identities
+// which do not satisfy the @authorize expression of the route with 403.
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/maadiii/hertz/server"
+)
+
+// Supported signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// Config configures an Identifier. At least one of Keys, JWKSFile or JWKSURL must be set.
+type Config struct {
+	// Keys are the static verification keys by key id. A key is a []byte secret for HS256,
+	// an *rsa.PublicKey for RS256 or an *ecdsa.PublicKey for ES256. The key of the
+	// empty id verifies the tokens without a kid header. ES256 keys must be of the
+	// P-256 curve. HS256 secrets are only accepted here, the symmetric keys of the
+	// JWKS sources are ignored.
+	Keys map[string]any
+	// JWKSFile is the path of a JSON Web Key Set file.
+	JWKSFile string
+	// JWKSURL is the URL of a JSON Web Key Set.
+	JWKSURL string
+	// RefreshInterval is the interval the key set is loaded again, 1h by default.
+	// Tokens signed by an unknown key also load it again, at most once a minute.
+	RefreshInterval time.Duration
+	// HTTPClient fetches JWKSURL, http.DefaultClient by default.
+	HTTPClient *http.Client
+
+	// Algorithms are the accepted signing algorithms, all of the supported ones by default.
+	Algorithms []string
+	// Issuer is the required iss claim, if set.
+	Issuer string
+	// Audience is the required aud claim, if set.
+	Audience string
+	// Leeway is the clock skew allowed on exp, nbf and iat.
+	Leeway time.Duration
+
+	// Claims are the paths of the claims mapped to the identity.
+	Claims ClaimPaths
+	// Header is the header of the token, Authorization by default. Its value may have the Bearer scheme.
+	Header string
+}
+
+// ClaimPaths are the dot separated paths of the claims mapped to server.Identity,
+// such as `realm_access.roles`.
+type ClaimPaths struct {
+	// ID is the path of Identity.ID, sub by default.
+	ID string
+	// Role is the path of Identity.Role, role by default. If the claim is a list,
+	// Role is its first item and Roles are the others.
+	Role string
+	// Permissions is the path of Identity.Permissions, permissions by default.
+	// A string claim is split by spaces, as the scope claim.
+	Permissions string
+	// Data maps the keys of Identity.Data to claim paths. All of the claims are
+	// set in Data by default.
+	Data map[string]string
+}
+
+// Identifier authenticates requests by their bearer tokens.
+type Identifier struct {
+	cfg  Config
+	keys *keySet
+	now  func() time.Time
+}
+
+// New creates an Identifier and loads its key sets.
+func New(cfg Config) (*Identifier, error) {
+	if len(cfg.Keys) == 0 && cfg.JWKSFile == "" && cfg.JWKSURL == "" {
+		return nil, errors.New("jwt: no keys, set Keys, JWKSFile or JWKSURL")
+	}
+
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = []string{HS256, RS256, ES256}
+	}
+
+	for _, alg := range cfg.Algorithms {
+		if alg != HS256 && alg != RS256 && alg != ES256 {
+			return nil, fmt.Errorf("jwt: unsupported algorithm %s", alg)
+		}
+	}
+
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = time.Hour
+	}
+
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	if cfg.Header == "" {
+		cfg.Header = "Authorization"
+	}
+
+	cfg.Claims = cfg.Claims.withDefaults()
+
+	keys, err := newKeySet(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Identifier{cfg: cfg, keys: keys, now: time.Now}, nil
+}
+
+// Identify is the identifier of a server. It verifies the token of the request and
+// sets its identity, which the server authorizes by the @authorize expression of the route.
+func (i *Identifier) Identify(c context.Context, req *server.Request, _ []string, _ ...string) {
+	token := i.token(req)
+	if token == "" {
+		i.unauthorized(c, req, "", "missing bearer token")
+
+		return
+	}
+
+	claims, err := i.verify(c, token)
+	if err != nil {
+		i.unauthorized(c, req, "invalid_token", err.Error())
+
+		return
+	}
+
+	req.SetIdentity(i.identity(claims))
+}
+
+// Verify verifies token and returns its claims.
+func (i *Identifier) Verify(c context.Context, token string) (map[string]any, error) {
+	return i.verify(c, token)
+}
+
+func (i *Identifier) token(req *server.Request) string {
+	value := strings.TrimSpace(string(req.GetHeader(i.cfg.Header)))
+
+	if scheme, token, ok := strings.Cut(value, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+
+	if strings.EqualFold(i.cfg.Header, "Authorization") {
+		return ""
+	}
+
+	return value
+}
+
+func (i *Identifier) unauthorized(c context.Context, req *server.Request, code, detail string) {
+	challenge := "Bearer"
+	if code != "" {
+		challenge += fmt.Sprintf(` error=%q, error_description=%q`, code, detail)
+	}
+
+	req.SetHeader("WWW-Authenticate", challenge)
+	req.Fail(c, server.Unauthorized(detail))
+}
@@ -0,0 +1,357 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	rsaKey = sync.OnceValue(func() *rsa.PrivateKey {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(err)
+		}
+
+		return key
+	})
+	ecKey = sync.OnceValue(func() *ecdsa.PrivateKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+
+		return key
+	})
+	secret = []byte("0123456789abcdef0123456789abcdef")
+)
+
+func encode(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// sign returns a token of the claims signed by key with alg.
+func sign(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	t.Helper()
+
+	signed := encode(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+
+	switch alg {
+	case HS256:
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case RS256:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case ES256:
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func b64(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "RSA", "kid": kid, "n": b64(key.N), "e": b64(big.NewInt(int64(key.E)))}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "EC", "kid": kid, "crv": "P-256", "x": b64(key.X), "y": b64(key.Y)}
+}
+
+// jwksServer serves the key set returned by keys, and counts its fetches.
+func jwksServer(t *testing.T, keys func() []map[string]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var fetches atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys()})
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &fetches
+}
+
+func validClaims() map[string]any {
+	return map[string]any{"sub": "42", "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func TestVerify(t *testing.T) {
+	identifier, err := New(Config{Keys: map[string]any{
+		"hs": secret,
+		"rs": &rsaKey().PublicKey,
+		"es": &ecKey().PublicKey,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := map[string]string{
+		HS256: sign(t, HS256, "hs", secret, validClaims()),
+		RS256: sign(t, RS256, "rs", rsaKey(), validClaims()),
+		ES256: sign(t, ES256, "es", ecKey(), validClaims()),
+	}
+
+	for alg, token := range tokens {
+		claims, err := identifier.Verify(context.Background(), token)
+		if err != nil {
+			t.Errorf("%s: %v", alg, err)
+
+			continue
+		}
+
+		if claims["sub"] != "42" {
+			t.Errorf("%s: sub = %v", alg, claims["sub"])
+		}
+	}
+
+	tampered := tokens[RS256]
+	tampered = tampered[:len(tampered)-4] + "AAAA"
+
+	if _, err := identifier.Verify(context.Background(), tampered); err == nil {
+		t.Error("a tampered signature is verified")
+	}
+}
+
+func TestAlgorithmConfusion(t *testing.T) {
+	rsaPub := &rsaKey().PublicKey
+
+	srv, _ := jwksServer(t, func() []map[string]string {
+		return []map[string]string{
+			rsaJWK("rs", rsaPub),
+			{"kty": "oct", "kid": "oct", "k": base64.RawURLEncoding.EncodeToString(secret)},
+		}
+	})
+
+	identifier, err := New(Config{JWKSURL: srv.URL, Keys: map[string]any{"es": &ecKey().PublicKey}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		// the public RSA key used as the secret of an HMAC.
+		"HS256 with an RSA key": sign(t, HS256, "rs", rsaPub.N.Bytes(), validClaims()),
+		// a symmetric key of a public key set may be known to anyone.
+		"HS256 with a JWKS oct key":  sign(t, HS256, "oct", secret, validClaims()),
+		"RS256 with an EC key":       sign(t, RS256, "es", rsaKey(), validClaims()),
+		"ES256 with an RSA key":      sign(t, ES256, "rs", ecKey(), validClaims()),
+		"none":                       encode(map[string]string{"alg": "none", "kid": "rs"}) + "." + encode(validClaims()) + ".",
+		"unsupported algorithm":      encode(map[string]string{"alg": "RS512", "kid": "rs"}) + "." + encode(validClaims()) + ".AAAA",
+		"malformed token":            "a.b",
+		"malformed header":           "e30K!.e30.AAAA",
+		"RS256 with an unknown kid":  sign(t, RS256, "unknown", rsaKey(), validClaims()),
+		"RS256 without a kid header": sign(t, RS256, "", rsaKey(), validClaims()),
+	}
+
+	for name, token := range tests {
+		if _, err := identifier.Verify(context.Background(), token); err == nil {
+			t.Errorf("%s: the token is verified", name)
+		}
+	}
+
+	restricted, err := New(Config{Keys: map[string]any{"": secret}, Algorithms: []string{RS256}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := restricted.Verify(context.Background(), sign(t, HS256, "", secret, validClaims())); err == nil {
+		t.Error("an HS256 token is verified when only RS256 is accepted")
+	}
+}
+
+func TestClaims(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+		valid  bool
+	}{
+		{name: "valid", claims: map[string]any{"exp": now.Add(time.Minute).Unix(), "iss": "issuer", "aud": "api"}, valid: true},
+		{name: "expired", claims: map[string]any{"exp": now.Add(-time.Minute).Unix(), "iss": "issuer", "aud": "api"}},
+		{name: "expired within leeway", claims: map[string]any{"exp": now.Add(-10 * time.Second).Unix(), "iss": "issuer", "aud": "api"}, valid: true},
+		{name: "not valid yet", claims: map[string]any{"nbf": now.Add(time.Minute).Unix(), "iss": "issuer", "aud": "api"}},
+		{name: "valid since", claims: map[string]any{"nbf": now.Add(-time.Minute).Unix(), "iss": "issuer", "aud": "api"}, valid: true},
+		{name: "issued in the future", claims: map[string]any{"iat": now.Add(time.Minute).Unix(), "iss": "issuer", "aud": "api"}},
+		{name: "other issuer", claims: map[string]any{"iss": "other", "aud": "api"}},
+		{name: "no issuer", claims: map[string]any{"aud": "api"}},
+		{name: "audience list", claims: map[string]any{"iss": "issuer", "aud": []string{"web", "api"}}, valid: true},
+		{name: "other audience", claims: map[string]any{"iss": "issuer", "aud": []string{"web"}}},
+		{name: "no audience", claims: map[string]any{"iss": "issuer"}},
+	}
+
+	identifier, err := New(Config{Keys: map[string]any{"": secret}, Issuer: "issuer", Audience: "api", Leeway: 30 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identifier.now = func() time.Time { return now }
+
+	for _, tt := range tests {
+		_, err := identifier.Verify(context.Background(), sign(t, HS256, "", secret, tt.claims))
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: error = %v, want valid %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey := &rsaKey().PublicKey
+	newKey := ecKey()
+
+	var (
+		mu      sync.Mutex
+		current = []map[string]string{rsaJWK("old", oldKey)}
+	)
+
+	srv, fetches := jwksServer(t, func() []map[string]string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return current
+	})
+
+	identifier, err := New(Config{JWKSURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := identifier.Verify(context.Background(), sign(t, RS256, "old", rsaKey(), validClaims())); err != nil {
+		t.Fatalf("old key: %v", err)
+	}
+
+	mu.Lock()
+	current = []map[string]string{rsaJWK("old", oldKey), ecJWK("new", &newKey.PublicKey)}
+	mu.Unlock()
+
+	token := sign(t, ES256, "new", newKey, validClaims())
+
+	// the key set is loaded again at most once a minute.
+	if _, err := identifier.Verify(context.Background(), token); err == nil {
+		t.Error("the new key is loaded within a minute of the last load")
+	}
+
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetches = %d, want 1", n)
+	}
+
+	identifier.keys.mu.Lock()
+	identifier.keys.attemptedAt = identifier.keys.attemptedAt.Add(-minRefreshInterval)
+	identifier.keys.mu.Unlock()
+
+	if _, err := identifier.Verify(context.Background(), token); err != nil {
+		t.Errorf("new key: %v", err)
+	}
+
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d, want 2", n)
+	}
+}
+
+func TestMalformedJWKSKeyIsSkipped(t *testing.T) {
+	srv, _ := jwksServer(t, func() []map[string]string {
+		return []map[string]string{
+			{"kty": "RSA", "kid": "bad", "n": "!!", "e": "AQAB"},
+			{"kty": "EC", "kid": "off-curve", "crv": "P-256", "x": "AQ", "y": "AQ"},
+			{"kty": "EC", "kid": "p384", "crv": "P-384", "x": "AQ", "y": "AQ"},
+			ecJWK("good", &ecKey().PublicKey),
+		}
+	})
+
+	identifier, err := New(Config{JWKSURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := identifier.Verify(context.Background(), sign(t, ES256, "good", ecKey(), validClaims())); err != nil {
+		t.Errorf("good key: %v", err)
+	}
+
+	if len(identifier.keys.keys) != 1 {
+		t.Errorf("keys = %v, want only the good one", identifier.keys.keys)
+	}
+}
+
+func TestStaticKeys(t *testing.T) {
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := map[string]any{
+		"P-384 key":     &p384.PublicKey,
+		"empty secret":  []byte{},
+		"string secret": "secret",
+		"private key":   rsaKey(),
+	}
+
+	for name, key := range invalid {
+		if _, err := New(Config{Keys: map[string]any{"kid": key}}); err == nil {
+			t.Errorf("%s is accepted", name)
+		}
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	identifier, err := New(Config{
+		Keys:   map[string]any{"": secret},
+		Claims: ClaimPaths{Role: "realm_access.roles", Permissions: "scope", Data: map[string]string{"email": "email"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := validClaims()
+	claims["realm_access"] = map[string]any{"roles": []string{"admin", "ops"}}
+	claims["scope"] = "orders:read orders:write"
+	claims["email"] = "a@example.com"
+
+	verified, err := identifier.Verify(context.Background(), sign(t, HS256, "", secret, claims))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity := identifier.identity(verified)
+
+	if identity.ID != "42" || identity.Role != "admin" || strings.Join(identity.Roles, ",") != "ops" ||
+		strings.Join(identity.Permissions, ",") != "orders:read,orders:write" || identity.Data["email"] != "a@example.com" {
+		t.Errorf("identity = %+v", identity)
+	}
+}
@@ -0,0 +1,268 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/maadiii/hertz/server"
+)
+
+var (
+	errMalformed = errors.New("malformed token")
+	errSignature = errors.New("invalid token signature")
+)
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// verify checks the signature and the registered claims of token.
+func (i *Identifier) verify(c context.Context, token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errMalformed
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, errMalformed
+	}
+
+	if !slices.Contains(i.cfg.Algorithms, h.Alg) {
+		return nil, fmt.Errorf("unaccepted algorithm %q", h.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errMalformed
+	}
+
+	key, err := i.keys.get(c, h.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(h.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errMalformed
+	}
+
+	if err := i.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
+
+func verifySignature(alg string, key any, signed, signature []byte) error {
+	digest := sha256.Sum256(signed)
+
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return fmt.Errorf("key is not a %s key", alg)
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errSignature
+		}
+	case RS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key is not a %s key", alg)
+		}
+
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) != nil {
+			return errSignature
+		}
+	case ES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != elliptic.P256() || len(signature) != 64 {
+			return errSignature
+		}
+
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return errSignature
+		}
+	}
+
+	return nil
+}
+
+func (i *Identifier) validateClaims(claims map[string]any) error {
+	now := i.now()
+
+	if exp, ok := numericDate(claims["exp"]); ok && !now.Before(exp.Add(i.cfg.Leeway)) {
+		return errors.New("token is expired")
+	}
+
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(i.cfg.Leeway).Before(nbf) {
+		return errors.New("token is not valid yet")
+	}
+
+	if iat, ok := numericDate(claims["iat"]); ok && now.Add(i.cfg.Leeway).Before(iat) {
+		return errors.New("token is issued in the future")
+	}
+
+	if i.cfg.Issuer != "" && claims["iss"] != i.cfg.Issuer {
+		return errors.New("token has an invalid issuer")
+	}
+
+	if i.cfg.Audience != "" && !slices.Contains(listOf(claims["aud"]), i.cfg.Audience) {
+		return errors.New("token has an invalid audience")
+	}
+
+	return nil
+}
+
+func numericDate(v any) (time.Time, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, int64(f*float64(time.Second))), true
+}
+
+func (p ClaimPaths) withDefaults() ClaimPaths {
+	if p.ID == "" {
+		p.ID = "sub"
+	}
+
+	if p.Role == "" {
+		p.Role = "role"
+	}
+
+	if p.Permissions == "" {
+		p.Permissions = "permissions"
+	}
+
+	return p
+}
+
+// identity maps the claims to an identity.
+func (i *Identifier) identity(claims map[string]any) server.Identity {
+	paths := i.cfg.Claims
+
+	identity := server.Identity{Permissions: stringsOf(lookup(claims, paths.Permissions))}
+
+	if id, ok := lookup(claims, paths.ID).(string); ok {
+		identity.ID = id
+	} else if n, ok := lookup(claims, paths.ID).(json.Number); ok {
+		identity.ID = n.String()
+	}
+
+	roles := listOf(lookup(claims, paths.Role))
+	if len(roles) > 0 {
+		identity.Role, identity.Roles = roles[0], roles[1:]
+	}
+
+	if paths.Data == nil {
+		identity.Data = claims
+
+		return identity
+	}
+
+	identity.Data = make(map[string]any, len(paths.Data))
+
+	for key, path := range paths.Data {
+		if v := lookup(claims, path); v != nil {
+			identity.Data[key] = v
+		}
+	}
+
+	return identity
+}
+
+// lookup returns the claim of a dot separated path. Claim names containing dots,
+// such as URLs, are matched before the path is split.
+func lookup(claims map[string]any, path string) any {
+	if v, ok := claims[path]; ok {
+		return v
+	}
+
+	for i := range path {
+		if path[i] != '.' {
+			continue
+		}
+
+		if nested, ok := claims[path[:i]].(map[string]any); ok {
+			if v := lookup(nested, path[i+1:]); v != nil {
+				return v
+			}
+		}
+	}
+
+	return nil
+}
+
+// stringsOf returns the strings of a list claim, or the space separated fields of a string claim.
+func stringsOf(v any) []string {
+	if s, ok := v.(string); ok {
+		return strings.Fields(s)
+	}
+
+	return listOf(v)
+}
+
+// listOf returns the strings of a list claim, or a string claim as a list of it.
+func listOf(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+
+		return values
+	}
+
+	return nil
+}
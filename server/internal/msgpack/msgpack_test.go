@@ -0,0 +1,454 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decoder decodes MessagePack into nil, bool, int64, uint64, float32, float64,
+// string, []byte, []any, map[string]any and time.Time, to check the encoding.
+type decoder struct {
+	buf []byte
+}
+
+func unmarshal(b []byte) (any, error) {
+	d := &decoder{buf: b}
+
+	v, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(d.buf) > 0 {
+		return nil, fmt.Errorf("%d trailing bytes", len(d.buf))
+	}
+
+	return v, nil
+}
+
+func (d *decoder) next(n int) ([]byte, error) {
+	if len(d.buf) < n {
+		return nil, errors.New("unexpected end")
+	}
+
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+
+	return b, nil
+}
+
+func (d *decoder) size(n int) (int, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n {
+	case 1:
+		return int(b[0]), nil
+	case 2:
+		return int(binary.BigEndian.Uint16(b)), nil
+	}
+
+	return int(binary.BigEndian.Uint32(b)), nil
+}
+
+func (d *decoder) decode() (any, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c := b[0]; {
+	case c <= 0x7f:
+		return uint64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	case c&0xf0 == 0x90:
+		return d.array(int(c & 0x0f))
+	case c&0xf0 == 0x80:
+		return d.object(int(c & 0x0f))
+	}
+
+	widths := map[byte]int{
+		0xc4: 1, 0xc5: 2, 0xc6: 4, // bin
+		0xd9: 1, 0xda: 2, 0xdb: 4, // str
+		0xdc: 2, 0xdd: 4, // array
+		0xde: 2, 0xdf: 4, // map
+	}
+
+	switch c := b[0]; c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.size(widths[c])
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := d.next(n)
+
+		return bytes.Clone(b), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.size(widths[c])
+		if err != nil {
+			return nil, err
+		}
+
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.size(widths[c])
+		if err != nil {
+			return nil, err
+		}
+
+		return d.array(n)
+	case 0xde, 0xdf:
+		n, err := d.size(widths[c])
+		if err != nil {
+			return nil, err
+		}
+
+		return d.object(n)
+	case 0xca:
+		b, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), nil
+	case 0xcb:
+		b, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		b, err := d.next(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+
+		return uint64Of(b), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		b, err := d.next(1 << (c - 0xd0))
+		if err != nil {
+			return nil, err
+		}
+
+		u := uint64Of(b)
+		shift := 64 - 8*len(b)
+
+		return int64(u<<shift) >> shift, nil
+	case 0xc7:
+		b, err := d.next(2)
+		if err != nil {
+			return nil, err
+		}
+
+		if b[0] != 12 || b[1] != 0xff {
+			return nil, fmt.Errorf("unexpected extension %d of length %d", int8(b[1]), b[0])
+		}
+
+		ts, err := d.next(12)
+		if err != nil {
+			return nil, err
+		}
+
+		return time.Unix(int64(binary.BigEndian.Uint64(ts[4:])), int64(binary.BigEndian.Uint32(ts[:4]))), nil
+	}
+
+	return nil, fmt.Errorf("unexpected byte %#x", b[0])
+}
+
+func uint64Of(b []byte) uint64 {
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+
+	return u
+}
+
+func (d *decoder) str(n int) (any, error) {
+	b, err := d.next(n)
+
+	return string(b), err
+}
+
+func (d *decoder) array(n int) (any, error) {
+	values := make([]any, n)
+
+	for i := range values {
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = v
+	}
+
+	return values, nil
+}
+
+func (d *decoder) object(n int) (any, error) {
+	values := make(map[string]any, n)
+
+	for range n {
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := key.(string)
+		if !ok {
+			name = fmt.Sprint(key)
+		}
+
+		if values[name], err = d.decode(); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+func roundTrip(t *testing.T, v any) any {
+	t.Helper()
+
+	b, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal(%#v): %v", v, err)
+	}
+
+	decoded, err := unmarshal(b)
+	if err != nil {
+		t.Fatalf("unmarshal(Marshal(%#v)) = %x: %v", v, b, err)
+	}
+
+	return decoded
+}
+
+type level string
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(string(l))), nil
+}
+
+func TestRoundTripScalars(t *testing.T) {
+	tests := []struct {
+		in   any
+		want any
+	}{
+		{in: nil, want: nil},
+		{in: true, want: true},
+		{in: false, want: false},
+		{in: 0, want: uint64(0)},
+		{in: 127, want: uint64(127)},
+		{in: 128, want: uint64(128)},
+		{in: 255, want: uint64(255)},
+		{in: 256, want: uint64(256)},
+		{in: math.MaxUint16 + 1, want: uint64(math.MaxUint16 + 1)},
+		{in: uint64(math.MaxUint32 + 1), want: uint64(math.MaxUint32 + 1)},
+		{in: uint64(math.MaxUint64), want: uint64(math.MaxUint64)},
+		{in: -1, want: int64(-1)},
+		{in: -32, want: int64(-32)},
+		{in: -33, want: int64(-33)},
+		{in: math.MinInt8, want: int64(math.MinInt8)},
+		{in: math.MinInt8 - 1, want: int64(math.MinInt8 - 1)},
+		{in: math.MinInt16 - 1, want: int64(math.MinInt16 - 1)},
+		{in: math.MinInt32 - 1, want: int64(math.MinInt32 - 1)},
+		{in: int64(math.MinInt64), want: int64(math.MinInt64)},
+		{in: float32(1.5), want: float32(1.5)},
+		{in: 3.25, want: 3.25},
+		{in: "", want: ""},
+		{in: "héllo", want: "héllo"},
+		{in: strings.Repeat("a", 31), want: strings.Repeat("a", 31)},
+		{in: strings.Repeat("a", 32), want: strings.Repeat("a", 32)},
+		{in: strings.Repeat("a", 256), want: strings.Repeat("a", 256)},
+		{in: strings.Repeat("a", math.MaxUint16+1), want: strings.Repeat("a", math.MaxUint16+1)},
+		{in: []byte{1, 2, 3}, want: []byte{1, 2, 3}},
+		{in: bytes.Repeat([]byte{7}, 256), want: bytes.Repeat([]byte{7}, 256)},
+		{in: [2]byte{4, 5}, want: []byte{4, 5}},
+		{in: level("warn"), want: "WARN"},
+		{in: new(int), want: uint64(0)},
+		{in: (*int)(nil), want: nil},
+		{in: []int(nil), want: nil},
+		{in: map[string]int(nil), want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := roundTrip(t, tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("round trip of %#v = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRoundTripContainers(t *testing.T) {
+	long := make([]int, 16)
+	want := make([]any, 16)
+
+	for i := range long {
+		long[i] = i
+		want[i] = uint64(i)
+	}
+
+	if got := roundTrip(t, long); !reflect.DeepEqual(got, want) {
+		t.Errorf("16 items = %#v", got)
+	}
+
+	object := make(map[string]int, 16)
+	wantObject := make(map[string]any, 16)
+
+	for i := range 16 {
+		object[fmt.Sprint(i)] = i
+		wantObject[fmt.Sprint(i)] = uint64(i)
+	}
+
+	if got := roundTrip(t, object); !reflect.DeepEqual(got, wantObject) {
+		t.Errorf("16 keys = %#v", got)
+	}
+
+	nested := map[string]any{"a": []any{1, "b", map[string]any{"c": nil}}}
+	wantNested := map[string]any{"a": []any{uint64(1), "b", map[string]any{"c": nil}}}
+
+	if got := roundTrip(t, nested); !reflect.DeepEqual(got, wantNested) {
+		t.Errorf("nested = %#v", got)
+	}
+}
+
+type Base struct {
+	ID      int `json:"id"`
+	Created time.Time
+}
+
+type inner struct {
+	Note string `json:"note"`
+}
+
+type user struct {
+	Base
+	*inner
+	Name    string            `json:"name"`
+	Email   string            `json:"email,omitempty"`
+	Secret  string            `json:"-"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Manager *user             `json:"manager"`
+	private string
+}
+
+func TestRoundTripStruct(t *testing.T) {
+	created := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+
+	in := user{
+		Base:    Base{ID: 7, Created: created},
+		inner:   &inner{Note: "n"},
+		Name:    "Ada",
+		Secret:  "s",
+		Tags:    []string{"a", "b"},
+		Manager: &user{Name: "Grace"},
+		private: "p",
+	}
+
+	got := roundTrip(t, &in)
+
+	want := map[string]any{
+		"id":      uint64(7),
+		"Created": created.Local(),
+		"note":    "n",
+		"name":    "Ada",
+		"tags":    []any{"a", "b"},
+		"manager": map[string]any{
+			"id":      uint64(0),
+			"Created": time.Time{}.Local(),
+			"name":    "Grace",
+			"tags":    nil,
+			"manager": nil,
+		},
+	}
+
+	if !reflect.DeepEqual(normalizeTimes(got), normalizeTimes(want)) {
+		t.Errorf("struct = %#v, want %#v", got, want)
+	}
+}
+
+// normalizeTimes replaces the times by their instants, which DeepEqual compares apart from their locations.
+func normalizeTimes(v any) any {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UnixNano()
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			out[key] = normalizeTimes(value)
+		}
+
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, value := range v {
+			out[i] = normalizeTimes(value)
+		}
+
+		return out
+	}
+
+	return v
+}
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		in   any
+		want []byte
+	}{
+		{in: nil, want: []byte{0xc0}},
+		{in: 1, want: []byte{0x01}},
+		{in: -1, want: []byte{0xff}},
+		{in: 200, want: []byte{0xcc, 0xc8}},
+		{in: -200, want: []byte{0xd1, 0xff, 0x38}},
+		{in: "a", want: []byte{0xa1, 'a'}},
+		{in: []int{1, 2}, want: []byte{0x92, 0x01, 0x02}},
+		{in: map[string]bool{"t": true}, want: []byte{0x81, 0xa1, 't', 0xc3}},
+		{in: 1.0, want: []byte{0xcb, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0}},
+		{
+			in:   time.Unix(1, 2),
+			want: []byte{0xc7, 12, 0xff, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := Marshal(tt.in)
+		if err != nil {
+			t.Errorf("Marshal(%#v): %v", tt.in, err)
+
+			continue
+		}
+
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("Marshal(%#v) = %x, want %x", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnsupportedType(t *testing.T) {
+	for _, v := range []any{make(chan int), func() {}, complex(1, 2), map[string]any{"f": func() {}}} {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("Marshal(%T) is supported", v)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Package resptest is a stand-in server of the Redis serialization protocol, to
+// test the clients of package resp without a Redis server.
+package resptest
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Handler answers a command. The reply is a Status, a string, an int or int64, a
+// []any of replies, an error or nil.
+type Handler func(args []string) any
+
+// Status is a simple string reply, such as OK.
+type Status string
+
+// Server answers the commands of its connections by a handler, one at a time.
+type Server struct {
+	ln      net.Listener
+	handler Handler
+
+	mu       sync.Mutex
+	handling sync.Mutex
+	conns    int
+	maxConns int
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server on a local port.
+func NewServer(handler Handler) (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{ln: ln, handler: handler}
+
+	s.wg.Add(1)
+
+	go s.serve()
+
+	return s, nil
+}
+
+// Addr returns the address of the server.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// URL returns the redis:// URL of the server.
+func (s *Server) URL() string {
+	return "redis://" + s.Addr()
+}
+
+// MaxConns returns the maximum of connections open at once.
+func (s *Server) MaxConns() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.maxConns
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	s.wg.Wait()
+
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		nc, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns++
+		s.maxConns = max(s.maxConns, s.conns)
+		s.mu.Unlock()
+
+		go s.handle(nc)
+	}
+}
+
+func (s *Server) handle(nc net.Conn) {
+	defer func() {
+		_ = nc.Close()
+
+		s.mu.Lock()
+		s.conns--
+		s.mu.Unlock()
+	}()
+
+	r := bufio.NewReader(nc)
+	w := bufio.NewWriter(nc)
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		s.handling.Lock()
+		reply := s.handler(args)
+		s.handling.Unlock()
+
+		writeReply(w, reply)
+
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("resptest: unexpected command %q", line)
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+
+	for i := range args {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.Atoi(strings.TrimPrefix(line, "$"))
+		if err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+
+		args[i] = string(buf[:size])
+	}
+
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+
+	return strings.TrimSuffix(line, "\r\n"), err
+}
+
+func writeReply(w *bufio.Writer, reply any) {
+	switch reply := reply.(type) {
+	case nil:
+		w.WriteString("$-1\r\n")
+	case Status:
+		fmt.Fprintf(w, "+%s\r\n", reply)
+	case error:
+		fmt.Fprintf(w, "-%s\r\n", reply)
+	case string:
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(reply), reply)
+	case int:
+		fmt.Fprintf(w, ":%d\r\n", reply)
+	case int64:
+		fmt.Fprintf(w, ":%d\r\n", reply)
+	case []any:
+		fmt.Fprintf(w, "*%d\r\n", len(reply))
+
+		for _, item := range reply {
+			writeReply(w, item)
+		}
+	default:
+		fmt.Fprintf(w, "-ERR resptest: unsupported reply %T\r\n", reply)
+	}
+}
+
+// Scripts is the script cache of a stand-in, which runs the scripts by Go functions
+// keyed by their source.
+type Scripts struct {
+	mu     sync.Mutex
+	run    map[string]func(keys, args []string) any
+	loaded map[string]string
+	// Evals counts the EVAL commands, and EvalSHAs the EVALSHA commands.
+	Evals, EvalSHAs int
+}
+
+// NewScripts creates a script cache running the scripts of its sources.
+func NewScripts(run map[string]func(keys, args []string) any) *Scripts {
+	return &Scripts{run: run, loaded: make(map[string]string)}
+}
+
+// Handle answers the EVAL and EVALSHA commands, and reports whether args is one.
+// EVALSHA is answered with NOSCRIPT until the script is sent by EVAL.
+func (s *Scripts) Handle(args []string) (any, bool) {
+	if len(args) < 3 {
+		return nil, false
+	}
+
+	command := strings.ToUpper(args[0])
+	if command != "EVAL" && command != "EVALSHA" {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	numKeys, err := strconv.Atoi(args[2])
+	if err != nil || numKeys > len(args)-3 {
+		return errors.New("ERR invalid number of keys"), true
+	}
+
+	src := args[1]
+
+	if command == "EVALSHA" {
+		s.EvalSHAs++
+
+		var ok bool
+		if src, ok = s.loaded[args[1]]; !ok {
+			return errors.New("NOSCRIPT No matching script"), true
+		}
+	} else {
+		s.Evals++
+
+		sum := sha1.Sum([]byte(src))
+		s.loaded[hex.EncodeToString(sum[:])] = src
+	}
+
+	run, ok := s.run[src]
+	if !ok {
+		return errors.New("ERR unknown script"), true
+	}
+
+	return run(args[3:3+numKeys], args[3+numKeys:]), true
+}
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+const defaultHookTimeout = 10 * time.Second
+
+type hookFn func(ctx context.Context) error
+
+type worker struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// lifecycle holds the hooks and workers of a Server, run by the hertz server it builds.
+type lifecycle struct {
+	hookTimeout time.Duration
+	onStart     []hookFn
+	onShutdown  []hookFn
+	workers     []worker
+
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+	// stopping reports the shutdown of the server started.
+	stopping atomic.Bool
+}
+
+// OnStart adds a hook called before the server listens. The hooks are called in the
+// order they are added, each with the timeout set by SetHookTimeout, and the server
+// does not start if one of them fails.
+//
+//	s.OnStart(func(ctx context.Context) error {
+//		return db.PingContext(ctx)
+//	})
+func (s *Server) OnStart(hook func(ctx context.Context) error) {
+	s.lifecycle.onStart = append(s.lifecycle.onStart, hook)
+}
+
+// OnShutdown adds a hook called on the graceful shutdown of the server, once its
+// WebSocket sessions ended and its workers stopped. The hooks are called in the order
+// they are added, each with the timeout set by SetHookTimeout, even if waiting for the
+// sessions and workers used up the exit wait time of the server.
+//
+// The hooks run while hertz stops listening and waits for the requests in flight, so
+// a hook must not close what those requests still use. The server exits once the
+// exit wait time expires, even if the hooks did not return.
+func (s *Server) OnShutdown(hook func(ctx context.Context) error) {
+	s.lifecycle.onShutdown = append(s.lifecycle.onShutdown, hook)
+}
+
+// Go runs a worker in its own goroutine while the server runs. The workers start
+// after the OnStart hooks, and their context is cancelled on shutdown, which waits
+// for them up to the exit wait time of the server. An error or panic of a worker
+// is logged and does not stop the server.
+//
+//	s.Go("outbox", func(ctx context.Context) error {
+//		ticker := time.NewTicker(time.Second)
+//		defer ticker.Stop()
+//
+//		for {
+//			select {
+//			case <-ctx.Done():
+//				return nil
+//			case <-ticker.C:
+//				outbox.Flush(ctx)
+//			}
+//		}
+//	})
+func (s *Server) Go(name string, fn func(ctx context.Context) error) {
+	l := &s.lifecycle
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w := worker{name: name, fn: fn}
+	l.workers = append(l.workers, w)
+
+	// the workers added to a running server start at once.
+	if l.ctx != nil && !l.stopping.Load() {
+		l.start(w)
+	}
+}
+
+// SetHookTimeout sets the timeout of each OnStart and OnShutdown hook, 10s by default.
+func (s *Server) SetHookTimeout(timeout time.Duration) {
+	s.lifecycle.hookTimeout = timeout
+}
+
+// OnStart adds a hook called before the default Server listens.
+func OnStart(hook func(ctx context.Context) error) {
+	defaultServer.OnStart(hook)
+}
+
+// OnShutdown adds a hook called on the graceful shutdown of the default Server.
+func OnShutdown(hook func(ctx context.Context) error) {
+	defaultServer.OnShutdown(hook)
+}
+
+// Go runs a worker in its own goroutine while the default Server runs.
+func Go(name string, fn func(ctx context.Context) error) {
+	defaultServer.Go(name, fn)
+}
+
+// SetHookTimeout sets the timeout of each OnStart and OnShutdown hook, 10s by default.
+func SetHookTimeout(timeout time.Duration) {
+	defaultServer.SetHookTimeout(timeout)
+}
+
+// run calls the OnStart hooks and starts the workers. It is an OnRun hook of the
+// hertz server, which does not listen if it returns an error.
+func (l *lifecycle) run(c context.Context) error {
+	for _, hook := range l.onStart {
+		if err := l.call(c, hook); err != nil {
+			return fmt.Errorf("server: start hook %s: %w", hookName(hook), err)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stopping.Store(false)
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+
+	for _, w := range l.workers {
+		l.start(w)
+	}
+
+	return nil
+}
+
+func (l *lifecycle) start(w worker) {
+	ctx := l.ctx
+
+	l.running.Add(1)
+
+	go func() {
+		defer l.running.Done()
+
+		defer func() {
+			if r := recover(); r != nil {
+				hlog.CtxErrorf(ctx, "%s worker panicked: %v\n%s", w.name, r, debug.Stack())
+			}
+		}()
+
+		if err := w.fn(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			hlog.CtxErrorf(ctx, "%s worker: %v", w.name, err)
+		}
+	}()
+}
+
+// shutdown closes the WebSocket connections, then stops the lifecycle. It is the
+// OnShutdown hook of the hertz server, which calls it concurrently with the shutdown
+// of its transport, and c expires with its exit wait time.
+func (s *Server) shutdown(c context.Context) {
+	s.webSockets.shutdown(c)
+	s.lifecycle.shutdown(c)
+}
+
+// shutdown cancels the workers and waits for them until c is done, then calls the
+// OnShutdown hooks, each with its own hook timeout.
+func (l *lifecycle) shutdown(c context.Context) {
+	l.mu.Lock()
+	l.stopping.Store(true)
+
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.mu.Unlock()
+
+	stopped := make(chan struct{})
+
+	go func() {
+		l.running.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-c.Done():
+		hlog.CtxErrorf(c, "the workers did not stop before the exit wait time")
+	}
+
+	// the hooks do not share what is left of c, which may be expired already.
+	hookCtx := context.WithoutCancel(c)
+
+	for _, hook := range l.onShutdown {
+		if err := l.call(hookCtx, hook); err != nil {
+			hlog.CtxErrorf(c, "shutdown hook %s: %v", hookName(hook), err)
+		}
+	}
+}
+
+// call calls a hook with the hook timeout.
+func (l *lifecycle) call(c context.Context, hook hookFn) error {
+	timeout := l.hookTimeout
+	if timeout <= 0 {
+		timeout = defaultHookTimeout
+	}
+
+	return callTimeout(c, timeout, hook)
+}
+
+// callTimeout calls fn with a context expiring after timeout. fn is abandoned once
+// the timeout expires, even if it ignores its context.
+func callTimeout(c context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	c, cancel := context.WithTimeout(c, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("%v\n%v", r, string(debug.Stack()))
+			}
+		}()
+
+		done <- fn(c)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-c.Done():
+		return fmt.Errorf("did not return in time: %w", c.Err())
+	}
+}
+
+// hookName returns the function name of a hook for its errors.
+func hookName(hook hookFn) string {
+	return strings.TrimSuffix(runtimeFunc(hook).Name(), "-fm")
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// calls records the hooks and workers of a test in the order they are called.
+type calls struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (c *calls) add(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.names = append(c.names, name)
+}
+
+func (c *calls) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return strings.Join(c.names, " ")
+}
+
+func (c *calls) hook(name string, err error) func(context.Context) error {
+	return func(context.Context) error {
+		c.add(name)
+
+		return err
+	}
+}
+
+func TestLifecycleStart(t *testing.T) {
+	s := New()
+	called := new(calls)
+
+	s.OnStart(called.hook("migrate", nil))
+	s.OnStart(called.hook("ping", errors.New("connection refused")))
+	s.OnStart(called.hook("warm", nil))
+	s.Go("outbox", called.hook("outbox", nil))
+
+	err := s.lifecycle.run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "start hook") || !strings.HasSuffix(err.Error(), "connection refused") {
+		t.Errorf("run() = %v, want the error of the failing hook", err)
+	}
+
+	s.lifecycle.running.Wait()
+
+	if got := called.String(); got != "migrate ping" {
+		t.Errorf("called %q, want the hooks up to the failing one and no worker", got)
+	}
+}
+
+func TestLifecycleHookTimeout(t *testing.T) {
+	s := New()
+	s.SetHookTimeout(10 * time.Millisecond)
+
+	// the hook ignores its context, and is abandoned once it expires.
+	s.OnStart(func(context.Context) error {
+		time.Sleep(time.Second)
+
+		return nil
+	})
+
+	start := time.Now()
+
+	err := s.lifecycle.run(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("run() = %v after %v, want the hook timed out", err, time.Since(start))
+	}
+}
+
+func TestLifecycleShutdown(t *testing.T) {
+	s := New()
+	called := new(calls)
+
+	s.OnStart(called.hook("start", nil))
+	s.OnShutdown(called.hook("flush", nil))
+	s.OnShutdown(called.hook("close", errors.New("already closed")))
+	s.Go("poller", func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		called.add("poller")
+
+		return ctx.Err()
+	})
+	s.Go("crasher", func(context.Context) error {
+		panic("a worker panic does not stop the server")
+	})
+
+	if err := s.lifecycle.run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// a worker added to the running server starts at once.
+	s.Go("late", func(ctx context.Context) error {
+		<-ctx.Done()
+		called.add("late")
+
+		return nil
+	})
+
+	s.shutdown(context.Background())
+
+	got := called.String()
+	if !strings.HasPrefix(got, "start ") || !strings.HasSuffix(got, " flush close") || !strings.Contains(got, "poller") || !strings.Contains(got, "late") {
+		t.Errorf("called %q, want the workers stopped before the shutdown hooks", got)
+	}
+}
+
+func TestLifecycleShutdownExitWait(t *testing.T) {
+	s := New()
+	stuck := make(chan struct{})
+	defer close(stuck)
+
+	var hookErr error
+
+	s.Go("stuck", func(context.Context) error {
+		<-stuck
+
+		return nil
+	})
+	s.OnShutdown(func(ctx context.Context) error {
+		hookErr = ctx.Err()
+
+		return nil
+	})
+
+	if err := s.lifecycle.run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	s.shutdown(c)
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("shutdown waited %v for a stuck worker, want up to the exit wait time", elapsed)
+	}
+
+	if hookErr != nil {
+		t.Errorf("the shutdown hook got %v, want its own timeout", hookErr)
+	}
+}
@@ -0,0 +1,221 @@
+package server
+
+import (
+	"context"
+	"encoding/xml"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	hjson "github.com/cloudwego/hertz/pkg/common/json"
+	"github.com/maadiii/hertz/server/internal/msgpack"
+	"gopkg.in/yaml.v3"
+)
+
+type encoder struct {
+	contentType string
+	encode      func(v any) ([]byte, error)
+}
+
+// builtinEncoders are the encoders of every Server.
+var builtinEncoders = map[string]encoder{
+	"json":    {contentType: "application/json; charset=utf-8", encode: hjson.Marshal},
+	"xml":     {contentType: "application/xml; charset=utf-8", encode: xml.Marshal},
+	"yaml":    {contentType: "application/yaml; charset=utf-8", encode: yaml.Marshal},
+	"msgpack": {contentType: "application/msgpack", encode: msgpack.Marshal},
+}
+
+var defaultNegotiateEncoders = []string{"json", "xml"}
+
+// encoderSet holds the encoders the negotiate responders of a Server select by name.
+type encoderSet struct {
+	mu       sync.RWMutex
+	encoders map[string]encoder
+}
+
+func newEncoderSet() *encoderSet {
+	return &encoderSet{encoders: maps.Clone(builtinEncoders)}
+}
+
+func (e *encoderSet) get(name string) (encoder, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	enc, ok := e.encoders[name]
+
+	return enc, ok
+}
+
+func (e *encoderSet) add(name string, enc encoder) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.encoders[name] = enc
+}
+
+// AddEncoder registers an encoder the negotiate responders of the server can select
+// by name, besides json, xml, yaml and msgpack. Validate reports the names of the
+// negotiate responders which are not registered.
+//
+//	s.AddEncoder("cbor", "application/cbor", cbor.Marshal)
+//
+//	// [GET] /users 200 negotiate(json,cbor)
+func (s *Server) AddEncoder(name, contentType string, encode func(v any) ([]byte, error)) {
+	s.encoders.add(name, encoder{contentType: contentType, encode: encode})
+}
+
+// AddEncoder registers an encoder the negotiate responders of the default Server can select by name.
+func AddEncoder(name, contentType string, encode func(v any) ([]byte, error)) {
+	defaultServer.AddEncoder(name, contentType, encode)
+}
+
+// negotiateEncoders returns the encoder names of a `negotiate(json,xml)` responder type.
+func negotiateEncoders(responderType string) ([]string, bool) {
+	args, ok := strings.CutPrefix(responderType, "negotiate")
+	if !ok {
+		return nil, false
+	}
+
+	args = strings.TrimSuffix(strings.TrimPrefix(args, "("), ")")
+	if args == "" {
+		return defaultNegotiateEncoders, true
+	}
+
+	return strings.Split(args, ","), true
+}
+
+func (h *Handler[IN, OUT]) setNegotiateResponder() bool {
+	names, ok := negotiateEncoders(h.ResponderType)
+	if !ok {
+		return false
+	}
+
+	h.RespondFn = func(_ context.Context, ctx *app.RequestContext, res any) error {
+		ctx.Response.Header.Add("Vary", "Accept")
+
+		offers := make([]encoder, 0, len(names))
+		contentTypes := make([]string, 0, len(names))
+
+		encoders := serverOrDefault(ctx).encoders
+
+		for _, name := range names {
+			if enc, ok := encoders.get(name); ok {
+				offers = append(offers, enc)
+				contentTypes = append(contentTypes, enc.contentType)
+			}
+		}
+
+		i := negotiate(string(ctx.GetHeader("Accept")), contentTypes)
+		if i < 0 {
+			acceptable := make([]string, 0, len(contentTypes))
+			for _, contentType := range contentTypes {
+				acceptable = append(acceptable, mediaType(contentType))
+			}
+
+			return NewHTTPError(http.StatusNotAcceptable, "acceptable types are "+strings.Join(acceptable, ", "))
+		}
+
+		body, err := offers[i].encode(res)
+		if err != nil {
+			return err
+		}
+
+		ctx.Data(h.status(ctx), offers[i].contentType, body)
+
+		return nil
+	}
+
+	return true
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// negotiate returns the index of the offered content type the Accept header prefers,
+// or -1 if none of them is acceptable. Offers are preferred in order on equal quality.
+func negotiate(accept string, offers []string) int {
+	if len(offers) == 0 {
+		return -1
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return 0
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ := -1, 0.0
+
+	for i, offer := range offers {
+		q := acceptQuality(ranges, mediaType(offer))
+		if q > bestQ {
+			best, bestQ = i, q
+		}
+	}
+
+	return best
+}
+
+// acceptQuality returns the quality of the most specific range matching the media type.
+func acceptQuality(ranges []acceptRange, offer string) float64 {
+	specificity, q := -1, 0.0
+
+	for _, r := range ranges {
+		var s int
+
+		switch {
+		case r.mediaType == offer:
+			s = 2
+		case strings.HasSuffix(r.mediaType, "/*") &&
+			strings.HasPrefix(offer, strings.TrimSuffix(r.mediaType, "*")):
+			s = 1
+		case r.mediaType == "*/*":
+			s = 0
+		default:
+			continue
+		}
+
+		if s > specificity {
+			specificity, q = s, r.q
+		}
+	}
+
+	return q
+}
+
+func parseAccept(accept string) []acceptRange {
+	ranges := make([]acceptRange, 0)
+
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		r := acceptRange{mediaType: mediaType(params[0]), q: 1}
+
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if key != "q" {
+				continue
+			}
+
+			if q, err := strconv.ParseFloat(value, 64); err == nil {
+				r.q = q
+			}
+		}
+
+		if r.mediaType != "" {
+			ranges = append(ranges, r)
+		}
+	}
+
+	return ranges
+}
+
+// mediaType returns the lower cased media type of a content type without its parameters.
+func mediaType(contentType string) string {
+	t, _, _ := strings.Cut(contentType, ";")
+
+	return strings.ToLower(strings.TrimSpace(t))
+}
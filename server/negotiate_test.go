@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+// negotiated is negotiated between json and csv.
+//
+// [GET] /negotiated 200 negotiate(json,csv)
+func negotiated(_ context.Context, _ *Request, _ *struct{}) ([]string, error) {
+	return []string{"a", "b"}, nil
+}
+
+func encodeCSV(v any) ([]byte, error) {
+	return []byte(strings.Join(v.([]string), ",")), nil
+}
+
+func TestNegotiateEncoders(t *testing.T) {
+	s := New()
+	s.AddEncoder("csv", "text/csv", encodeCSV)
+	s.Register(NewAction(negotiated))
+
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := s.Build().Engine
+
+	tests := []struct {
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{accept: "text/csv", status: http.StatusOK, contentType: "text/csv", body: "a,b"},
+		{accept: "application/json", status: http.StatusOK, contentType: "application/json; charset=utf-8", body: `["a","b"]`},
+		{accept: "", status: http.StatusOK, contentType: "application/json; charset=utf-8", body: `["a","b"]`},
+		{accept: "text/csv;q=0.5, application/*;q=0.4", status: http.StatusOK, contentType: "text/csv", body: "a,b"},
+		{accept: "application/xml", status: http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		res := ut.PerformRequest(engine, http.MethodGet, "/negotiated", nil, ut.Header{Key: "Accept", Value: tt.accept})
+		if res.Code != tt.status {
+			t.Errorf("Accept %q: status = %d, want %d", tt.accept, res.Code, tt.status)
+
+			continue
+		}
+
+		if tt.status != http.StatusOK {
+			continue
+		}
+
+		if contentType := res.Header().Get("Content-Type"); contentType != tt.contentType || res.Body.String() != tt.body {
+			t.Errorf("Accept %q = %s %q, want %s %q", tt.accept, contentType, res.Body.String(), tt.contentType, tt.body)
+		}
+	}
+
+	// the encoders of a server are not shared with the others.
+	other := New()
+	other.Register(NewAction(negotiated))
+
+	if err := other.Validate(); err == nil || !strings.Contains(err.Error(), `unknown encoder "csv"`) {
+		t.Errorf("Validate() = %v, want the unknown csv encoder", err)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/json", "application/xml", "application/msgpack"}
+
+	tests := map[string]int{
+		"":                                 0,
+		"*/*":                              0,
+		"application/xml":                  1,
+		"application/*":                    0,
+		"application/msgpack, */*;q=0.1":   2,
+		"application/xml;q=0.9, */*;q=0.8": 1,
+		"application/json;q=0, */*":        1,
+		"text/html":                        -1,
+		"APPLICATION/XML; charset=utf-8":   1,
+		"application/*;q=0.5, application/msgpack": 2,
+	}
+
+	for accept, want := range tests {
+		if got := negotiate(accept, offers); got != want {
+			t.Errorf("negotiate(%q) = %d, want %d", accept, got, want)
+		}
+	}
+
+	if got := negotiate("*/*", nil); got != -1 {
+		t.Errorf("negotiate without offers = %d, want -1", got)
+	}
+}
+
+func TestNegotiateOutOfServer(t *testing.T) {
+	// the handlers of a route mounted on another engine use the default encoders.
+	rctx := app.NewContext(0)
+	rctx.Request.SetMethod(http.MethodPost)
+	rctx.Request.SetRequestURI("/created/negotiated")
+	rctx.Request.Header.Set("Accept", "application/json")
+
+	NewAction(createdNegotiated).route.handle(New())(context.Background(), rctx)
+
+	if status, body := rctx.Response.StatusCode(), string(rctx.Response.Body()); status != http.StatusCreated || body != `{"name":"ann"}` {
+		t.Errorf("response = %d %s, want the json of the default encoders", status, body)
+	}
+}
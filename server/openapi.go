@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/maadiii/hertz/server/openapi"
+)
+
+const openAPIPath = "/openapi.json"
+
+// OpenAPI builds an OpenAPI 3.1 document from the handlers registered in the default Server.
+func OpenAPI(info openapi.Info) *openapi.Document {
+	return defaultServer.OpenAPI(info)
+}
+
+// ServeOpenAPI serves the document built by OpenAPI on /openapi.json.
+// If uiPath is not empty, a Swagger UI page of the document is served on it too.
+func ServeOpenAPI(info openapi.Info, uiPath string) {
+	defaultServer.ServeOpenAPI(info, uiPath)
+}
+
+// OpenAPI builds an OpenAPI 3.1 document from the registered handlers.
+func (s *Server) OpenAPI(info openapi.Info) *openapi.Document {
+	builder := openapi.NewBuilder(info)
+
+	for _, r := range s.routes {
+		builder.Add(r.openAPIRoute())
+	}
+
+	return builder.Document()
+}
+
+// ServeOpenAPI serves the document built by OpenAPI on /openapi.json.
+// If uiPath is not empty, a Swagger UI page of the document is served on it too,
+// which loads the Swagger UI assets from openapi.SwaggerUIAssets, unless they are
+// set by SetSwaggerUIAssets.
+func (s *Server) ServeOpenAPI(info openapi.Info, uiPath string) {
+	s.openAPIInfo = &info
+	s.swaggerUIPath = uiPath
+}
+
+// SetSwaggerUIAssets sets the base URL of the Swagger UI scripts and styles loaded by
+// the page of ServeOpenAPI, such as a self-hosted copy of the swagger-ui-dist package
+// for the browsers which cannot reach unpkg.com.
+func (s *Server) SetSwaggerUIAssets(baseURL string) {
+	s.swaggerUIAssets = baseURL
+}
+
+// SetSwaggerUIAssets sets the base URL of the Swagger UI assets of the default Server.
+func SetSwaggerUIAssets(baseURL string) {
+	defaultServer.SetSwaggerUIAssets(baseURL)
+}
+
+func (r *routeDescriber) openAPIRoute() openapi.Route {
+	contentTypes, binary := r.responseContentTypes()
+
+	route := openapi.Route{
+		Method:       r.Verb,
+		Path:         r.FullPath(),
+		OperationID:  strings.TrimSuffix(strings.TrimPrefix(path.Ext(r.FunctionName), "."), "-fm"),
+		Summary:      r.Summary,
+		Description:  r.Description,
+		Status:       r.Status,
+		In:           r.In,
+		Out:          r.Out,
+		ContentTypes: contentTypes,
+		Binary:       binary,
+		Policies:     r.Policies,
+	}
+
+	if describer := r.authorization(); describer != nil {
+		route.Authenticated = true
+		route.Authorize = describer.Expr.String()
+		route.Roles = describer.Roles
+		route.Permissions = describer.Permissions
+	}
+
+	return route
+}
+
+func (s *Server) openAPIHandlers() map[string]app.HandlerFunc {
+	if s.openAPIInfo == nil {
+		return nil
+	}
+
+	info := *s.openAPIInfo
+	document := sync.OnceValue(func() *openapi.Document { return s.OpenAPI(info) })
+	handlers := map[string]app.HandlerFunc{
+		openAPIPath: func(_ context.Context, rctx *app.RequestContext) {
+			rctx.JSON(http.StatusOK, document())
+		},
+	}
+
+	if s.swaggerUIPath != "" {
+		assets := s.swaggerUIAssets
+		if assets == "" {
+			assets = openapi.SwaggerUIAssets
+		}
+
+		page := openapi.SwaggerUIFrom(openAPIPath, assets)
+		handlers[s.swaggerUIPath] = func(_ context.Context, rctx *app.RequestContext) {
+			rctx.Data(http.StatusOK, "text/html; charset=utf-8", page)
+		}
+	}
+
+	return handlers
+}
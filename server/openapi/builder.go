@@ -0,0 +1,600 @@
+package openapi
+
+import (
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Route describes a registered handler to the Builder.
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	Status      int
+	In          reflect.Type
+	Out         reflect.Type
+	// ContentTypes of the response body. Empty means the response has no body.
+	ContentTypes []string
+	// Binary reports the response body is written as raw bytes instead of an encoded Out.
+	Binary bool
+	// Authenticated reports the route requires an identity, and Authorize is the
+	// expression the identity must satisfy, if any.
+	Authenticated bool
+	Authorize     string
+	Roles         []string
+	Permissions   []string
+	// Policies are the names of the policies authorizing the bound input.
+	Policies []string
+}
+
+// Builder builds a Document out of routes, reflecting their IN and OUT types.
+type Builder struct {
+	doc   *Document
+	names map[reflect.Type]string
+	// operationIDs are the ids of the added operations.
+	operationIDs map[string]bool
+}
+
+func NewBuilder(info Info) *Builder {
+	return &Builder{
+		doc: &Document{
+			OpenAPI:    Version,
+			Info:       info,
+			Paths:      make(map[string]*PathItem),
+			Components: &Components{Schemas: make(map[string]*Schema)},
+		},
+		names:        make(map[reflect.Type]string),
+		operationIDs: make(map[string]bool),
+	}
+}
+
+// Document returns the built document.
+func (b *Builder) Document() *Document {
+	return b.doc
+}
+
+var pathParam = regexp.MustCompile(`[:*]([^/]+)`)
+
+// Add adds the operation of a route to the document.
+func (b *Builder) Add(r Route) {
+	p := pathParam.ReplaceAllString(r.Path, "{$1}")
+	if p == "" {
+		p = "/"
+	}
+
+	item, ok := b.doc.Paths[p]
+	if !ok {
+		item = new(PathItem)
+		b.doc.Paths[p] = item
+	}
+
+	op := item.Operation(r.Method)
+	if op == nil {
+		return
+	}
+
+	op.OperationID = b.operationID(r.OperationID)
+	op.Summary = r.Summary
+	op.Description = r.Description
+	op.Tags = r.Tags
+	op.Authorize = r.Authorize
+	op.Roles = r.Roles
+	op.Permissions = r.Permissions
+	op.Policies = r.Policies
+
+	if r.In != nil {
+		b.addInput(op, r.In, r.Method)
+	}
+
+	res := &Response{Description: http.StatusText(r.Status)}
+	if len(r.ContentTypes) > 0 {
+		schema := &Schema{Type: "string", Format: "binary"}
+		if !r.Binary && r.Out != nil {
+			schema = b.schema(r.Out)
+		}
+
+		res.Content = make(map[string]*MediaType)
+		for _, contentType := range r.ContentTypes {
+			res.Content[contentType] = &MediaType{Schema: schema}
+		}
+	}
+
+	if r.Out != nil {
+		res.Headers = b.responseHeaders(r.Out)
+	}
+
+	op.Responses[strconv.Itoa(r.Status)] = res
+
+	if r.Authenticated {
+		op.Responses["401"] = &Response{Description: http.StatusText(http.StatusUnauthorized)}
+	}
+
+	if r.Authorize != "" || len(r.Roles) > 0 || len(r.Permissions) > 0 || len(r.Policies) > 0 {
+		op.Responses["403"] = &Response{Description: http.StatusText(http.StatusForbidden)}
+	}
+}
+
+// operationID returns id, numbered if an added operation has it, as the handlers
+// of other packages or receivers with the same name, or a handler registered twice.
+func (b *Builder) operationID(id string) string {
+	if id == "" {
+		return ""
+	}
+
+	for i, numbered := 2, id; b.operationIDs[id]; i++ {
+		id = numbered + strconv.Itoa(i)
+	}
+
+	b.operationIDs[id] = true
+
+	return id
+}
+
+// bodylessMethods are the methods whose requests have no body, which bind the untagged
+// fields of their IN struct from the query.
+var bodylessMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+func (b *Builder) addInput(op *Operation, t reflect.Type, method string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	body := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	form := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	b.walkInput(op, t, body, form, bodylessMethods[strings.ToUpper(method)])
+
+	op.RequestBody = nil
+	content := make(map[string]*MediaType)
+
+	if len(body.Properties) > 0 {
+		content["application/json"] = &MediaType{Schema: body}
+	}
+
+	if len(form.Properties) > 0 {
+		content["multipart/form-data"] = &MediaType{Schema: form}
+		content["application/x-www-form-urlencoded"] = &MediaType{Schema: form}
+	}
+
+	if len(content) > 0 {
+		op.RequestBody = &RequestBody{
+			Required: len(body.Required) > 0 || len(form.Required) > 0,
+			Content:  content,
+		}
+	}
+}
+
+var parameterTags = []string{"path", "query", "header", "cookie"}
+
+// walkInput adds the fields of t to the parameters of op and to the body and form
+// schemas. Untagged fields are bound from the query if bodyless, else from the body.
+func (b *Builder) walkInput(op *Operation, t reflect.Type, body, form *Schema, bodyless bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || streamedTypes[typeName(field.Type)] {
+			continue
+		}
+
+		if field.Anonymous && !hasBindingTag(field) {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				b.walkInput(op, ft, body, form, bodyless)
+
+				continue
+			}
+		}
+
+		bound := false
+
+		for _, in := range parameterTags {
+			name, ok := tagName(field, in)
+			if !ok {
+				continue
+			}
+
+			bound = true
+			schema := b.schema(field.Type)
+			required := applyValidation(schema, field.Tag.Get("validate"))
+
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name:     name,
+				In:       in,
+				Required: required || in == "path",
+				Schema:   schema,
+			})
+		}
+
+		if name, ok := tagName(field, "form"); ok {
+			bound = true
+			addProperty(form, name, b.schema(field.Type), field.Tag.Get("validate"))
+		}
+
+		name, ok := tagName(field, "json")
+
+		switch {
+		case ok:
+			addProperty(body, name, b.schema(field.Type), field.Tag.Get("validate"))
+		case bound:
+		case bodyless:
+			schema := b.schema(field.Type)
+
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name:     field.Name,
+				In:       "query",
+				Required: applyValidation(schema, field.Tag.Get("validate")),
+				Schema:   schema,
+			})
+		default:
+			addProperty(body, field.Name, b.schema(field.Type), field.Tag.Get("validate"))
+		}
+	}
+}
+
+func addProperty(object *Schema, name string, schema *Schema, rules string) {
+	if applyValidation(schema, rules) {
+		object.Required = append(object.Required, name)
+	}
+
+	object.Properties[name] = schema
+}
+
+func hasBindingTag(field reflect.StructField) bool {
+	for _, tag := range append(parameterTags, "form", "json") {
+		if _, ok := field.Tag.Lookup(tag); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func tagName(field reflect.StructField, key string) (string, bool) {
+	tag, ok := field.Tag.Lookup(key)
+	if !ok {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return "", false
+	}
+
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// statusTypeName is the type of the OUT fields setting the response status.
+const statusTypeName = "github.com/maadiii/hertz/server.Status"
+
+// isResponseField reports whether an OUT field is set on the response instead of its body.
+func isResponseField(field reflect.StructField) bool {
+	if typeName(field.Type) == statusTypeName {
+		return true
+	}
+
+	for _, tag := range []string{"header", "cookie"} {
+		if name := field.Tag.Get(tag); name != "" && name != "-" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// responseHeaders describes the header and cookie fields of an OUT struct.
+func (b *Builder) responseHeaders(t reflect.Type) map[string]*Header {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	headers := make(map[string]*Header)
+	b.addHeaders(headers, t)
+
+	if len(headers) == 0 {
+		return nil
+	}
+
+	return headers
+}
+
+// addHeaders adds the header and cookie fields of t and of the structs it embeds.
+func (b *Builder) addHeaders(headers map[string]*Header, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if name := field.Tag.Get("header"); name != "" && name != "-" {
+			headers[name] = &Header{Schema: b.schema(field.Type)}
+
+			continue
+		}
+
+		if name := field.Tag.Get("cookie"); name != "" && name != "-" {
+			headers["Set-Cookie"] = &Header{Schema: &Schema{Type: "string"}}
+
+			continue
+		}
+
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				b.addHeaders(headers, ft)
+			}
+		}
+	}
+}
+
+// fileTypes are the types of uploaded files, described as binary strings.
+var fileTypes = map[string]bool{
+	"mime/multipart.FileHeader":            true,
+	"github.com/maadiii/hertz/server.File": true,
+}
+
+// streamedTypes are the types reading the body as it arrives, which have no schema.
+var streamedTypes = map[string]bool{
+	"github.com/maadiii/hertz/server.Parts": true,
+}
+
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.PkgPath() + "." + t.Name()
+}
+
+func (b *Builder) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	if fileTypes[typeName(t)] {
+		return &Schema{Type: "string", Format: "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+
+		return &Schema{Ref: "#/components/schemas/" + b.component(t)}
+	default:
+		return &Schema{}
+	}
+}
+
+func (b *Builder) component(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+
+	// the types of other packages with the same name are prefixed by their package,
+	// and numbered if the prefixed name is taken too.
+	name := componentName(t.Name())
+	if b.taken(name) {
+		name = componentName(path.Base(t.PkgPath())) + name
+	}
+
+	for i, prefixed := 2, name; b.taken(name); i++ {
+		name = prefixed + strconv.Itoa(i)
+	}
+
+	b.names[t] = name
+	b.doc.Components.Schemas[name] = &Schema{}
+	*b.doc.Components.Schemas[name] = *b.object(t)
+
+	return name
+}
+
+func (b *Builder) taken(name string) bool {
+	_, ok := b.doc.Components.Schemas[name]
+
+	return ok
+}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+func componentName(name string) string {
+	return strings.Trim(nonIdentifier.ReplaceAllString(name, "_"), "_")
+}
+
+func (b *Builder) object(t reflect.Type) *Schema {
+	object := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	b.addFields(object, t)
+
+	return object
+}
+
+func (b *Builder) addFields(object *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || isResponseField(field) {
+			continue
+		}
+
+		name, ok := tagName(field, "json")
+		if !ok {
+			if _, skipped := field.Tag.Lookup("json"); skipped {
+				continue
+			}
+
+			name = field.Name
+		}
+
+		if field.Anonymous && !ok {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				b.addFields(object, ft)
+
+				continue
+			}
+		}
+
+		addProperty(object, name, b.schema(field.Type), field.Tag.Get("validate"))
+	}
+}
+
+// applyValidation maps the go-playground validator rules to schema constraints.
+// It reports whether the value is required. A $ref schema gets no constraints,
+// which cannot be siblings of the reference.
+func applyValidation(schema *Schema, rules string) (required bool) {
+	if rules == "" {
+		return false
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		if schema.Ref != "" && name != "required" && name != "dive" {
+			continue
+		}
+
+		switch name {
+		case "dive":
+			return required
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "ipv4", "ipv6", "hostname":
+			schema.Format = name
+		case "datetime":
+			schema.Format = "date-time"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, enumValue(schema, v))
+			}
+		case "min", "gte":
+			setBound(schema, param, false, false)
+		case "max", "lte":
+			setBound(schema, param, true, false)
+		case "gt":
+			setBound(schema, param, false, true)
+		case "lt":
+			setBound(schema, param, true, true)
+		case "len":
+			setBound(schema, param, false, false)
+			setBound(schema, param, true, false)
+		}
+	}
+
+	return required
+}
+
+func enumValue(schema *Schema, v string) any {
+	switch schema.Type {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+
+	return v
+}
+
+func setBound(schema *Schema, param string, upper, exclusive bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		switch {
+		case upper && exclusive:
+			schema.ExclusiveMaximum = &n
+		case upper:
+			schema.Maximum = &n
+		case exclusive:
+			schema.ExclusiveMinimum = &n
+		default:
+			schema.Minimum = &n
+		}
+	case "string", "array":
+		i := int(n)
+		if exclusive && upper {
+			i--
+		} else if exclusive {
+			i++
+		}
+
+		length := &schema.MinLength
+		switch {
+		case schema.Type == "array" && upper:
+			length = &schema.MaxItems
+		case schema.Type == "array":
+			length = &schema.MinItems
+		case upper:
+			length = &schema.MaxLength
+		}
+
+		*length = &i
+	}
+}
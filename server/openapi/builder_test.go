@@ -0,0 +1,193 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type user struct {
+	ID        int       `json:"id"`
+	Email     string    `json:"email" validate:"required,email"`
+	CreatedAt time.Time `json:"createdAt"`
+	Location  string    `header:"Location"`
+	Secret    string    `json:"-"`
+}
+
+type getUserRequest struct {
+	ID       int    `path:"id"`
+	Fields   string `query:"fields"`
+	Language string `header:"Accept-Language"`
+	Expand   bool
+}
+
+type createUserRequest struct {
+	Email string   `json:"email" validate:"required,email"`
+	Age   int      `json:"age" validate:"min=18,max=130"`
+	Role  string   `json:"role" validate:"oneof=admin user"`
+	Tags  []string `json:"tags" validate:"max=3"`
+	Note  string
+}
+
+func TestBuilderAdd(t *testing.T) {
+	b := NewBuilder(Info{Title: "users", Version: "1"})
+	b.Add(Route{
+		Method: http.MethodGet, Path: "/users/:id", OperationID: "GetUser", Status: http.StatusOK,
+		In: reflect.TypeOf(&getUserRequest{}), Out: reflect.TypeOf(&user{}), ContentTypes: []string{"application/json"},
+		Authenticated: true, Roles: []string{"admin"},
+	})
+
+	item, ok := b.Document().Paths["/users/{id}"]
+	if !ok || item.Get == nil {
+		t.Fatalf("paths = %v, want the GET of /users/{id}", b.Document().Paths)
+	}
+
+	op := item.Get
+
+	var params []string
+	for _, p := range op.Parameters {
+		params = append(params, p.In+":"+p.Name)
+
+		if p.Name == "id" && (!p.Required || p.Schema.Type != "integer") {
+			t.Errorf("path param id = %+v, want a required integer", p)
+		}
+	}
+
+	if want := []string{"path:id", "query:fields", "header:Accept-Language", "query:Expand"}; !slices.Equal(params, want) {
+		t.Errorf("parameters = %v, want %v", params, want)
+	}
+
+	if op.RequestBody != nil {
+		t.Errorf("request body of a GET = %+v, want none", op.RequestBody)
+	}
+
+	res := op.Responses["200"]
+	if res == nil || res.Content["application/json"].Schema.Ref != "#/components/schemas/user" {
+		t.Fatalf("200 response = %+v, want the user component", res)
+	}
+
+	if _, ok := res.Headers["Location"]; !ok {
+		t.Errorf("response headers = %v, want Location", res.Headers)
+	}
+
+	schema := b.Document().Components.Schemas["user"]
+	if _, ok := schema.Properties["Location"]; ok {
+		t.Error("the header field is a property of the body")
+	}
+
+	if _, ok := schema.Properties["Secret"]; ok {
+		t.Error("the json:\"-\" field is a property of the body")
+	}
+
+	if schema.Properties["createdAt"].Format != "date-time" || schema.Properties["email"].Format != "email" {
+		t.Errorf("properties = %+v, want the date-time and email formats", schema.Properties)
+	}
+
+	if !slices.Equal(schema.Required, []string{"email"}) {
+		t.Errorf("required = %v, want email", schema.Required)
+	}
+
+	for _, status := range []string{"401", "403"} {
+		if op.Responses[status] == nil {
+			t.Errorf("responses = %v, want %s", op.Responses, status)
+		}
+	}
+}
+
+func TestBuilderRequestBody(t *testing.T) {
+	b := NewBuilder(Info{})
+	b.Add(Route{Method: http.MethodPost, Path: "/users", Status: http.StatusCreated, In: reflect.TypeOf(&createUserRequest{})})
+
+	op := b.Document().Paths["/users"].Post
+	if op.RequestBody == nil || !op.RequestBody.Required {
+		t.Fatalf("request body = %+v, want a required body", op.RequestBody)
+	}
+
+	body := op.RequestBody.Content["application/json"].Schema
+
+	if _, ok := body.Properties["Note"]; !ok {
+		t.Error("the untagged field of a POST is not in its body")
+	}
+
+	age := body.Properties["age"]
+	if age.Minimum == nil || *age.Minimum != 18 || age.Maximum == nil || *age.Maximum != 130 {
+		t.Errorf("age = %+v, want between 18 and 130", age)
+	}
+
+	if role := body.Properties["role"]; !slices.Equal(role.Enum, []any{"admin", "user"}) {
+		t.Errorf("role enum = %v", role.Enum)
+	}
+
+	if tags := body.Properties["tags"]; tags.MaxItems == nil || *tags.MaxItems != 3 {
+		t.Errorf("tags = %+v, want at most 3 items", tags)
+	}
+
+	if res := op.Responses["201"]; res == nil || res.Content != nil {
+		t.Errorf("201 response = %+v, want one without a body", res)
+	}
+}
+
+func TestBuilderOperationID(t *testing.T) {
+	b := NewBuilder(Info{})
+
+	for _, path := range []string{"/v1/users", "/v2/users", "/v3/users"} {
+		b.Add(Route{Method: http.MethodGet, Path: path, OperationID: "ListUsers", Status: http.StatusOK})
+	}
+
+	b.Add(Route{Method: http.MethodGet, Path: "/anonymous", Status: http.StatusOK})
+
+	var ids []string
+	for _, path := range []string{"/v1/users", "/v2/users", "/v3/users", "/anonymous"} {
+		ids = append(ids, b.Document().Paths[path].Get.OperationID)
+	}
+
+	if want := []string{"ListUsers", "ListUsers2", "ListUsers3", ""}; !slices.Equal(ids, want) {
+		t.Errorf("operation ids = %q, want %q", ids, want)
+	}
+}
+
+// packageUser is the package-level user, shadowed in TestBuilderComponentNames.
+var packageUser = reflect.TypeOf(user{})
+
+func TestBuilderComponentNames(t *testing.T) {
+	// the local user has the name and package of the package-level one.
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	b := NewBuilder(Info{})
+	for path, out := range map[string]reflect.Type{
+		"/a": packageUser,
+		"/b": reflect.TypeOf(&user{}),
+		"/c": reflect.TypeOf(struct{ U *user }{}),
+		"/d": reflect.PointerTo(packageUser),
+	} {
+		b.Add(Route{Method: http.MethodGet, Path: path, Status: http.StatusOK, Out: out, ContentTypes: []string{"application/json"}})
+	}
+
+	var names []string
+	for name := range b.Document().Components.Schemas {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	if want := []string{"openapiuser", "user"}; !slices.Equal(names, want) {
+		t.Errorf("components = %v, want %v", names, want)
+	}
+}
+
+func TestSwaggerUI(t *testing.T) {
+	page := string(SwaggerUI("/openapi.json"))
+	if !strings.Contains(page, `url: "/openapi.json"`) || !strings.Contains(page, SwaggerUIAssets+"/swagger-ui-bundle.js") {
+		t.Errorf("page = %s, want the spec URL and the default assets", page)
+	}
+
+	page = string(SwaggerUIFrom("/spec.json", "/static/swagger/"))
+	if !strings.Contains(page, `href="/static/swagger/swagger-ui.css"`) || strings.Contains(page, "unpkg.com") {
+		t.Errorf("page = %s, want the self-hosted assets only", page)
+	}
+}
@@ -0,0 +1,154 @@
+// Package openapi describes OpenAPI 3.1 documents and reflects Go types into them.
+package openapi
+
+import (
+	_ "embed"
+	"html"
+	"strings"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+type PathItem struct {
+	Get     *Operation `json:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty"`
+	Options *Operation `json:"options,omitempty"`
+	Head    *Operation `json:"head,omitempty"`
+	Patch   *Operation `json:"patch,omitempty"`
+	Trace   *Operation `json:"trace,omitempty"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Authorize   string               `json:"x-authorize,omitempty"`
+	Roles       []string             `json:"x-roles,omitempty"`
+	Permissions []string             `json:"x-permissions,omitempty"`
+	Policies    []string             `json:"x-policies,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// Operation returns the operation of the path item for the given HTTP method.
+// It creates the operation if it does not exist yet.
+func (p *PathItem) Operation(method string) *Operation {
+	var op **Operation
+
+	switch strings.ToUpper(method) {
+	case "GET":
+		op = &p.Get
+	case "PUT":
+		op = &p.Put
+	case "POST":
+		op = &p.Post
+	case "DELETE":
+		op = &p.Delete
+	case "OPTIONS":
+		op = &p.Options
+	case "HEAD":
+		op = &p.Head
+	case "PATCH":
+		op = &p.Patch
+	case "TRACE":
+		op = &p.Trace
+	default:
+		return nil
+	}
+
+	if *op == nil {
+		*op = &Operation{Responses: make(map[string]*Response)}
+	}
+
+	return *op
+}
+
+//go:embed swagger.html
+var swaggerUI string
+
+// SwaggerUIAssets is the default base URL of the Swagger UI scripts and styles.
+const SwaggerUIAssets = "https://unpkg.com/swagger-ui-dist@5"
+
+// SwaggerUI returns an HTML page rendering the document served at specURL with Swagger UI.
+// Only the page is embedded: it loads the Swagger UI scripts and styles from SwaggerUIAssets,
+// so the browser showing it must be able to reach it.
+func SwaggerUI(specURL string) []byte {
+	return SwaggerUIFrom(specURL, SwaggerUIAssets)
+}
+
+// SwaggerUIFrom returns the page of SwaggerUI loading the swagger-ui.css and
+// swagger-ui-bundle.js files of assetsURL, such as a self-hosted copy of the
+// swagger-ui-dist package.
+func SwaggerUIFrom(specURL, assetsURL string) []byte {
+	return []byte(strings.NewReplacer(
+		"{{SPEC_URL}}", specURL,
+		"{{ASSETS_URL}}", html.EscapeString(strings.TrimRight(assetsURL, "/")),
+	).Replace(swaggerUI))
+}
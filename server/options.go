@@ -378,3 +378,32 @@ func WithDisableDefaultContentType(disable bool) config.Option {
 		o.NoDefaultContentType = disable
 	}}
 }
+
+// WithSenseClientDisconnection sets the ability to sense client disconnections.
+//
+// When enabled, the context of a request is cancelled once its client disconnects,
+// which ends the streams of sse handlers immediately. It only applies to netpoll.
+func WithSenseClientDisconnection(b bool) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.SenseClientDisconnection = b
+	}}
+}
+
+// WithHealth serves the probes of the health checks added by AddHealthCheck:
+//
+//   - /healthz runs all the checks.
+//   - /readyz runs all the checks, and fails once a graceful shutdown is signaled.
+//   - /livez runs the Liveness checks.
+//
+// A probe answers 200 with the JSON status of its checks, or 503 if a check which is
+// not Optional fails.
+//
+// On SIGINT or SIGHUP, /readyz fails for drainDelay before the server shuts down, so
+// load balancers stop sending it requests while it still serves them. The signal
+// waiter of the server is replaced for it, so SetCustomSignalWaiter disables the delay.
+func WithHealth(drainDelay time.Duration) config.Option {
+	return serverOption(func(s *Server) {
+		s.health.serve = true
+		s.health.drainDelay = drainDelay
+	})
+}
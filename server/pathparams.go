@@ -0,0 +1,151 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SetStrictPathParams sets whether the `path` tags of an IN struct which are not
+// params of the path of its route, such as the leftovers of a renamed param, are
+// reported by Validate. It applies to all the routes, whether they are registered
+// before or after it.
+//
+// The :params and *wildcards of a route missing from the `path` tags of its IN
+// struct are always reported.
+func (s *Server) SetStrictPathParams(strict bool) {
+	s.strictPathParams = strict
+}
+
+// SetStrictPathParams sets whether the unused `path` tags of IN structs are reported by Validate.
+func SetStrictPathParams(strict bool) {
+	defaultServer.SetStrictPathParams(strict)
+}
+
+// checkPathParams adds the params of the path missing from the `path` tags of the
+// IN struct to the errors of the route, and keeps the tags which are not params of
+// the path for the strict mode. An input which is not a struct binds no params and
+// is not checked.
+func (r *routeDescriber) checkPathParams() {
+	r.pathChecked = true
+
+	tags := pathTags(r.In)
+	if tags == nil {
+		return
+	}
+
+	path := r.FullPath()
+	params := pathParams(path)
+
+	used := make(map[string]bool, len(params))
+	for _, param := range params {
+		used[param] = true
+	}
+
+	var unused []string
+
+	for tag := range tags {
+		if !used[tag] {
+			unused = append(unused, tag)
+		}
+	}
+
+	slices.Sort(unused)
+
+	for _, param := range params {
+		if tags[param] {
+			continue
+		}
+
+		err := fmt.Errorf("path param %s is not bound by a `path:%q` field of %s", param, param, r.In)
+		if similar := similarTag(param, unused); similar != "" {
+			err = fmt.Errorf("%w, did you mean `path:%q`?", err, similar)
+		}
+
+		r.errs = append(r.errs, err)
+	}
+
+	for _, tag := range unused {
+		r.unusedPathTags = append(r.unusedPathTags, fmt.Errorf("`path:%q` field of %s is not a param of %s", tag, r.In, path))
+	}
+}
+
+// similarTag returns the tag which is likely a typo of param, differing in case or
+// by at most two edits, if any.
+func similarTag(param string, tags []string) string {
+	for _, tag := range tags {
+		if strings.EqualFold(tag, param) {
+			return tag
+		}
+	}
+
+	for _, tag := range tags {
+		if editDistance(strings.ToLower(tag), strings.ToLower(param)) <= 2 {
+			return tag
+		}
+	}
+
+	return ""
+}
+
+// editDistance returns the Levenshtein distance of a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+var pathParam = regexp.MustCompile(`[:*]([^/]+)`)
+
+// pathParams returns the names of the :param and *wildcard segments of path.
+func pathParams(path string) (params []string) {
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		params = append(params, match[1])
+	}
+
+	return params
+}
+
+// pathTags returns the names of the path tags of the in struct, or nil if in is not
+// a struct, such as the inputs binding nothing.
+func pathTags(in reflect.Type) map[string]bool {
+	for in != nil && in.Kind() == reflect.Pointer {
+		in = in.Elem()
+	}
+
+	if in == nil || in.Kind() != reflect.Struct {
+		return nil
+	}
+
+	tags := make(map[string]bool)
+
+	for _, field := range reflect.VisibleFields(in) {
+		if name, _, _ := strings.Cut(field.Tag.Get("path"), ","); name != "" && name != "-" {
+			tags[name] = true
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// serveAsset binds the wildcard of its path.
+//
+// [GET] /assets/*filepath 200 json
+func serveAsset(_ context.Context, _ *Request, _ *struct {
+	Filepath string `path:"filepath"`
+}) (map[string]bool, error) {
+	return nil, nil
+}
+
+// getMember misspells the param of its path, and binds the one of its group.
+//
+// @group /teams/:team
+// [GET] /members/:memberID 200 json
+func getMember(_ context.Context, _ *Request, _ *struct {
+	Team     string `path:"team"`
+	MemberId string `path:"memberId"`
+}) (map[string]bool, error) {
+	return nil, nil
+}
+
+// getPost keeps the tag of a renamed param.
+//
+// [GET] /posts/:slug 200 json
+func getPost(_ context.Context, _ *Request, _ *struct {
+	Slug string `path:"slug"`
+	ID   int    `path:"id"`
+}) (map[string]bool, error) {
+	return nil, nil
+}
+
+func TestPathParams(t *testing.T) {
+	s := New()
+	s.Register(NewAction(serveAsset), NewAction(getMember), NewAction(getPost))
+
+	// the group of getMember is created after its registration.
+	s.Group("/teams/:team")
+
+	err := s.Validate()
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Problems) != 1 {
+		t.Fatalf("Validate() = %v, want the misspelled param only", err)
+	}
+
+	if msg := err.Error(); !strings.Contains(msg, "path param memberID") || !strings.Contains(msg, "did you mean `path:\"memberId\"`?") {
+		t.Errorf("Validate() = %v, want memberId suggested", err)
+	}
+
+	// the strict mode applies to the routes registered before it.
+	s.SetStrictPathParams(true)
+
+	err = s.Validate()
+	if err == nil || !strings.Contains(err.Error(), "`path:\"id\"` field of") || strings.Contains(err.Error(), "`path:\"team\"`") {
+		t.Errorf("strict Validate() = %v, want the unused id tag only", err)
+	}
+
+	if strings.Count(err.Error(), "path param memberID") != 1 {
+		t.Errorf("strict Validate() = %v, want the missing param reported once", err)
+	}
+}
+
+func TestSimilarTag(t *testing.T) {
+	tests := []struct {
+		param string
+		tags  []string
+		want  string
+	}{
+		{param: "userID", tags: []string{"name", "userId"}, want: "userId"},
+		{param: "user_id", tags: []string{"userid"}, want: "userid"},
+		{param: "slug", tags: []string{"slugs"}, want: "slugs"},
+		{param: "id", tags: []string{"name"}, want: ""},
+		{param: "id", tags: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := similarTag(tt.param, tt.tags); got != tt.want {
+			t.Errorf("similarTag(%q, %q) = %q, want %q", tt.param, tt.tags, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type policyFn func(c context.Context, req *Request, in any) error
+
+// AddPolicy registers a policy which handlers require by the `@policy name` directive.
+// Policies run after the request is bound and validated, and before the handler.
+// The request is rejected with 403 through the ErrorHandler if the policy returns an
+// error, or with the status of the error if it is an HTTPError.
+//
+//	server.AddPolicy("ownsOrder", server.Policy(func(c context.Context, req *server.Request, in *GetOrderRequest) error {
+//		if !orders.OwnedBy(c, in.ID, req.Identity().ID) {
+//			return errors.New("the order is not owned by the identity")
+//		}
+//
+//		return nil
+//	}))
+//
+//	// [GET] /orders/:id 200 json
+//	// @authorize
+//	// @policy ownsOrder
+//	func GetOrder(c context.Context, req *server.Request, in *GetOrderRequest) (*Order, error)
+func (s *Server) AddPolicy(name string, f policyFn) {
+	s.policies[name] = f
+}
+
+// AddPolicy registers a policy which handlers require by the `@policy name` directive.
+func AddPolicy(name string, f policyFn) {
+	defaultServer.AddPolicy(name, f)
+}
+
+// Policy adapts a policy of the typed input of handlers. The policy denies the
+// requests of handlers with another input type.
+func Policy[IN any](f func(c context.Context, req *Request, in IN) error) policyFn {
+	return func(c context.Context, req *Request, in any) error {
+		typed, ok := in.(IN)
+		if !ok {
+			return fmt.Errorf("policy input %T is not %T", in, typed)
+		}
+
+		return f(c, req, typed)
+	}
+}
+
+// authorizeInput runs the policies of a handler on its bound input.
+func (s *Server) authorizeInput(c context.Context, req *Request, policies []string, in any) error {
+	for _, name := range policies {
+		policy, ok := s.policies[name]
+		if !ok {
+			return fmt.Errorf("policy %s does not exist", name)
+		}
+
+		if err := policy(c, req, in); err != nil {
+			var httpErr *HTTPError
+			if errors.As(err, &httpErr) {
+				return err
+			}
+
+			return Forbidden("denied by the " + name + " policy").Wrap(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+type getOrderRequest struct {
+	ID string `path:"id" validate:"min=2"`
+}
+
+// getOrder returns an order of the identity.
+//
+// @authorize
+// @policy ownsOrder
+// [GET] /orders/:id 200 json
+func getOrder(_ context.Context, _ *Request, in *getOrderRequest) (map[string]string, error) {
+	return map[string]string{"id": in.ID}, nil
+}
+
+// getInvoice returns an invoice, with a policy of another input type.
+//
+// @policy ownsOrder
+// [GET] /invoices/:id 200 json
+func getInvoice(_ context.Context, _ *Request, _ *struct {
+	ID string `path:"id"`
+}) (map[string]string, error) {
+	return nil, nil
+}
+
+func TestPolicy(t *testing.T) {
+	s := New()
+
+	var (
+		checked []string
+		handled error
+	)
+
+	s.SetIdentifier(identifyAs("u1"))
+	s.SetErrorHandler(func(c context.Context, rctx *app.RequestContext, err error) {
+		handled = err
+		ProblemErrorHandler(c, rctx, err)
+	})
+	s.AddPolicy("ownsOrder", Policy(func(_ context.Context, req *Request, in *getOrderRequest) error {
+		checked = append(checked, in.ID)
+
+		switch {
+		case in.ID == "missing":
+			return NotFound("the order does not exist")
+		case !strings.HasPrefix(in.ID, req.Identity().ID+"-"):
+			return errors.New("the order is not owned by the identity")
+		}
+
+		return nil
+	}))
+	s.Register(NewAction(getOrder), NewAction(getInvoice))
+
+	engine := s.Build().Engine
+
+	tests := []struct {
+		path   string
+		status int
+		detail string
+	}{
+		{path: "/orders/u1-7", status: http.StatusOK},
+		{path: "/orders/u2-7", status: http.StatusForbidden, detail: "denied by the ownsOrder policy"},
+		{path: "/orders/missing", status: http.StatusNotFound, detail: "the order does not exist"},
+		{path: "/invoices/u1-7", status: http.StatusForbidden, detail: "denied by the ownsOrder policy"},
+	}
+
+	for _, tt := range tests {
+		handled = nil
+
+		res := ut.PerformRequest(engine, http.MethodGet, tt.path, nil)
+		if res.Code != tt.status || !strings.Contains(res.Body.String(), tt.detail) {
+			t.Errorf("%s = %d %s, want %d %q", tt.path, res.Code, res.Body.String(), tt.status, tt.detail)
+		}
+
+		if (tt.status == http.StatusOK) != (handled == nil) {
+			t.Errorf("%s: ErrorHandler called with %v", tt.path, handled)
+		}
+	}
+
+	checked = nil
+
+	// an input failing the validation never reaches the policy.
+	if res := ut.PerformRequest(engine, http.MethodGet, "/orders/x", nil); res.Code != http.StatusBadRequest || len(checked) > 0 {
+		t.Errorf("invalid input = %d, policy checked %v, want 400 before the policy", res.Code, checked)
+	}
+}
+
+func TestPolicyUnknown(t *testing.T) {
+	s := New()
+	s.SetIdentifier(identifyAs("u1"))
+	s.Register(NewAction(getOrder))
+
+	var validationErr *ValidationError
+	if err := s.Validate(); !errors.As(err, &validationErr) || !strings.Contains(err.Error(), "policy ownsOrder does not exist") {
+		t.Errorf("Validate() = %v, want the unknown policy reported", err)
+	}
+}
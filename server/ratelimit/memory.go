@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	memoryShards  = 32
+	sweepInterval = time.Minute
+)
+
+// MemoryStore keeps the limits in memory, sharded by key to reduce lock contention.
+// Its limits are per process, so the instances of a service do not share them.
+type MemoryStore struct {
+	shards [memoryShards]memoryShard
+	now    func() time.Time
+}
+
+type memoryShard struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+	sweptAt time.Time
+}
+
+type memoryEntry struct {
+	// token bucket
+	tokens float64
+	at     time.Time
+	// sliding window
+	window     int64
+	curr, prev int64
+
+	expires time.Time
+}
+
+// NewMemoryStore creates a MemoryStore. The expired keys are removed as the store is used.
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{now: time.Now}
+
+	for i := range s.shards {
+		s.shards[i].entries = make(map[string]*memoryEntry)
+	}
+
+	return s
+}
+
+// Take takes a request of key.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	now := s.now()
+	shard := s.shard(key)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	shard.sweep(now)
+
+	entry, ok := shard.entries[key]
+	if !ok || !now.Before(entry.expires) {
+		entry = &memoryEntry{tokens: float64(limit.capacity()), at: now, window: now.UnixNano() / int64(limit.Period)}
+		shard.entries[key] = entry
+	}
+
+	if limit.Algorithm == SlidingWindow {
+		return entry.slidingWindow(now, limit), nil
+	}
+
+	return entry.tokenBucket(now, limit), nil
+}
+
+func (s *MemoryStore) shard(key string) *memoryShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return &s.shards[h.Sum32()%memoryShards]
+}
+
+// sweep removes the expired entries, once per sweepInterval.
+func (sh *memoryShard) sweep(now time.Time) {
+	if now.Sub(sh.sweptAt) < sweepInterval {
+		return
+	}
+
+	sh.sweptAt = now
+
+	for key, entry := range sh.entries {
+		if !now.Before(entry.expires) {
+			delete(sh.entries, key)
+		}
+	}
+}
+
+func (e *memoryEntry) tokenBucket(now time.Time, limit Limit) Result {
+	capacity := float64(limit.capacity())
+	rate := float64(limit.Requests) / float64(limit.Period)
+
+	e.tokens = math.Min(capacity, e.tokens+float64(now.Sub(e.at))*rate)
+	e.at = now
+
+	allowed := e.tokens >= 1
+	if allowed {
+		e.tokens--
+	}
+
+	e.expires = now.Add(time.Duration((capacity - e.tokens) / rate))
+
+	return tokenBucket(limit, e.tokens, allowed)
+}
+
+func (e *memoryEntry) slidingWindow(now time.Time, limit Limit) Result {
+	period := int64(limit.Period)
+	window := now.UnixNano() / period
+
+	switch window {
+	case e.window:
+	case e.window + 1:
+		e.window, e.prev, e.curr = window, e.curr, 0
+	default:
+		e.window, e.prev, e.curr = window, 0, 0
+	}
+
+	elapsed := time.Duration(now.UnixNano() - window*period)
+	weight := float64(period-int64(elapsed)) / float64(period)
+
+	allowed := float64(e.prev)*weight+float64(e.curr)+1 <= float64(limit.Requests)
+	if allowed {
+		e.curr++
+	}
+
+	e.expires = time.Unix(0, (window+2)*period)
+
+	return slidingWindow(limit, e.curr, e.prev, elapsed, allowed)
+}
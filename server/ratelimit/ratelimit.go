@@ -0,0 +1,291 @@
+// Package ratelimit is the `@ratelimit` decorator, limiting the requests of a route
+// per client IP, identity or header value.
+//
+// Unlike `@cache` and `@idempotent`, the decorator is not registered by server.New,
+// since it needs a Store. It must be registered by AddDecoratorFactory, otherwise
+// Validate reports `@ratelimit` as an unknown decorator and Build panics.
+//
+//	server.AddDecoratorFactory("ratelimit", ratelimit.Factory(ratelimit.NewMemoryStore()))
+//
+//	// [POST] /orders 201 json
+//	// @authorize
+//	// @ratelimit(100/m, key=identity, burst=20)
+//	func CreateOrder(c context.Context, req *server.Request, in *CreateOrderRequest) (*Order, error)
+//
+// The first argument is the rate, as `100/s`, `100/m`, `100/h`, `100/d` or `100/10s`.
+// The other arguments are optional:
+//
+//   - key: ip (the default), identity, or header:Name. Requests without an identity
+//     or the header are limited by their client IP.
+//   - algorithm: token-bucket (the default) or sliding-window.
+//   - burst: the capacity of the token bucket, the rate count by default.
+//
+// Responses have the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and
+// RateLimit-Policy headers. Limited requests are rejected with 429 and Retry-After
+// through the ErrorHandler.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maadiii/hertz/server"
+)
+
+// Algorithms of a Limit.
+const (
+	TokenBucket   = "token-bucket"
+	SlidingWindow = "sliding-window"
+)
+
+// Limit is the limit of a key.
+type Limit struct {
+	Algorithm string
+	// Requests are the requests allowed per Period.
+	Requests int
+	Period   time.Duration
+	// Burst is the capacity of the token bucket, Requests if zero. The sliding window ignores it.
+	Burst int
+}
+
+func (l Limit) capacity() int {
+	if l.Algorithm == TokenBucket && l.Burst > 0 {
+		return l.Burst
+	}
+
+	return l.Requests
+}
+
+// Result is the outcome of taking a request of a key.
+type Result struct {
+	Allowed bool
+	// Limit is the maximum of requests allowed at once.
+	Limit     int
+	Remaining int
+	// Reset is the time until the limit is fully available again.
+	Reset time.Duration
+	// RetryAfter is the time until a request is allowed, if it is not allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the state of the limited keys.
+type Store interface {
+	// Take takes a request of key and reports whether the limit allows it.
+	Take(c context.Context, key string, limit Limit) (Result, error)
+}
+
+// Factory creates the `@ratelimit` decorator of a store.
+func Factory(store Store) func(args []string) (server.DecoratorFunc, error) {
+	return func(args []string) (server.DecoratorFunc, error) {
+		limit, keyFn, err := parseArgs(args)
+		if err != nil {
+			return nil, err
+		}
+
+		// The limit is part of the key, so the limits of a group and its routes are apart.
+		prefix := fmt.Sprintf("ratelimit:%s:%d/%s:", limit.Algorithm, limit.Requests, limit.Period)
+
+		return func(c context.Context, req *server.Request) {
+			key := prefix + req.Method() + ":" + req.FullPath() + ":" + keyFn(req)
+
+			result, err := store.Take(c, key, limit)
+			if err != nil {
+				req.Fail(c, fmt.Errorf("ratelimit: %w", err))
+
+				return
+			}
+
+			setHeaders(req, limit, result)
+
+			if !result.Allowed {
+				req.SetHeader("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+				req.Fail(c, server.TooManyRequests("the rate limit is exceeded"))
+			}
+		}, nil
+	}
+}
+
+func setHeaders(req *server.Request, limit Limit, result Result) {
+	policy := fmt.Sprintf("%d;w=%d", limit.Requests, seconds(limit.Period))
+	if limit.capacity() != limit.Requests {
+		policy += fmt.Sprintf(";burst=%d", limit.capacity())
+	}
+
+	req.SetHeader("RateLimit-Limit", strconv.Itoa(result.Limit))
+	req.SetHeader("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	req.SetHeader("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+	req.SetHeader("RateLimit-Policy", policy)
+}
+
+// seconds rounds d up to whole seconds.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+type keyFn func(req *server.Request) string
+
+// parseArgs parses the `rate, key=..., algorithm=..., burst=...` arguments of the directive.
+func parseArgs(args []string) (Limit, keyFn, error) {
+	if len(args) == 0 {
+		return Limit{}, nil, errors.New("the rate is missing, as @ratelimit(100/m)")
+	}
+
+	limit, err := parseRate(args[0])
+	if err != nil {
+		return Limit{}, nil, err
+	}
+
+	limit.Algorithm = TokenBucket
+	key := keyByIP
+
+	for _, arg := range args[1:] {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return Limit{}, nil, fmt.Errorf("invalid argument %q, expected name=value", arg)
+		}
+
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		switch name {
+		case "key":
+			if key, err = parseKey(value); err != nil {
+				return Limit{}, nil, err
+			}
+		case "algorithm":
+			if value != TokenBucket && value != SlidingWindow {
+				return Limit{}, nil, fmt.Errorf("unknown algorithm %q", value)
+			}
+
+			limit.Algorithm = value
+		case "burst":
+			if limit.Burst, err = strconv.Atoi(value); err != nil || limit.Burst <= 0 {
+				return Limit{}, nil, fmt.Errorf("invalid burst %q", value)
+			}
+		default:
+			return Limit{}, nil, fmt.Errorf("unknown argument %q", name)
+		}
+	}
+
+	return limit, key, nil
+}
+
+var periods = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+}
+
+// parseRate parses a `100/m` or `100/10s` rate.
+func parseRate(rate string) (Limit, error) {
+	count, per, ok := strings.Cut(rate, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate %q, expected requests/period", rate)
+	}
+
+	requests, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || requests <= 0 {
+		return Limit{}, fmt.Errorf("invalid requests of rate %q", rate)
+	}
+
+	per = strings.TrimSpace(per)
+
+	period, ok := periods[per]
+	if !ok {
+		if period, err = time.ParseDuration(per); err != nil || period < time.Millisecond {
+			return Limit{}, fmt.Errorf("invalid period of rate %q", rate)
+		}
+	}
+
+	return Limit{Requests: requests, Period: period}, nil
+}
+
+func parseKey(key string) (keyFn, error) {
+	switch {
+	case key == "ip":
+		return keyByIP, nil
+	case key == "identity":
+		return func(req *server.Request) string {
+			if identity, ok := req.LookupIdentity(); ok && identity.ID != "" {
+				return "identity:" + identity.ID
+			}
+
+			return keyByIP(req)
+		}, nil
+	case strings.HasPrefix(key, "header:") && len(key) > len("header:"):
+		header := strings.TrimPrefix(key, "header:")
+
+		return func(req *server.Request) string {
+			if value := req.GetHeader(header); len(value) > 0 {
+				return "header:" + string(value)
+			}
+
+			return keyByIP(req)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown key %q, expected ip, identity or header:Name", key)
+}
+
+func keyByIP(req *server.Request) string {
+	return "ip:" + req.ClientIP()
+}
+
+// tokenBucket is the result of a token bucket left with tokens.
+func tokenBucket(limit Limit, tokens float64, allowed bool) Result {
+	capacity := limit.capacity()
+	perToken := limit.Period / time.Duration(limit.Requests)
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     capacity,
+		Remaining: int(tokens),
+		Reset:     time.Duration((float64(capacity) - tokens) * float64(perToken)),
+	}
+
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * float64(perToken))
+	}
+
+	return result
+}
+
+// slidingWindow is the result of a sliding window, where curr and prev are the counts
+// of the current and the previous windows and elapsed is the time into the current one.
+func slidingWindow(limit Limit, curr, prev int64, elapsed time.Duration, allowed bool) Result {
+	period := float64(limit.Period)
+	weight := (period - float64(elapsed)) / period
+	used := int(math.Ceil(float64(prev)*weight)) + int(curr)
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: max(limit.Requests-used, 0),
+		Reset:     limit.Period - elapsed,
+	}
+
+	if curr > 0 {
+		result.Reset += limit.Period
+	}
+
+	if allowed {
+		return result
+	}
+
+	// The previous window weighs less over time, until a request fits in the current one.
+	free := float64(int64(limit.Requests) - 1 - curr)
+	if free < 0 || prev == 0 {
+		result.RetryAfter = limit.Period - elapsed
+
+		return result
+	}
+
+	result.RetryAfter = max(time.Duration(period-free*period/float64(prev))-elapsed, time.Millisecond)
+
+	return result
+}
@@ -0,0 +1,298 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/maadiii/hertz/server"
+	"github.com/maadiii/hertz/server/internal/resp/resptest"
+	"github.com/maadiii/hertz/server/servertest"
+)
+
+// epoch starts a minute, so the windows of a minute start with the clock.
+var epoch = time.Unix(600, 0)
+
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time { return c.now }
+
+func newMemoryStore(clock *clock) Store {
+	s := NewMemoryStore()
+	s.now = clock.Now
+
+	return s
+}
+
+// newRedisStore returns a RedisStore of a stand-in server, which runs the scripts
+// of the store in Go on the clock.
+func newRedisStore(t *testing.T, clock *clock) Store {
+	t.Helper()
+
+	type bucket struct{ tokens, at float64 }
+
+	type window struct{ window, curr, prev int64 }
+
+	buckets := make(map[string]*bucket)
+	windows := make(map[string]*window)
+
+	scripts := resptest.NewScripts(map[string]func(keys, args []string) any{
+		tokenBucketScript.Src(): func(keys, args []string) any {
+			now := float64(clock.now.UnixMicro())
+			capacity, _ := strconv.ParseFloat(args[0], 64)
+			rate, _ := strconv.ParseFloat(args[1], 64)
+
+			b, ok := buckets[keys[0]]
+			if !ok {
+				b = &bucket{tokens: capacity, at: now}
+				buckets[keys[0]] = b
+			}
+
+			b.tokens = math.Min(capacity, b.tokens+math.Max(now-b.at, 0)*rate)
+			b.at = now
+
+			allowed := 0
+			if b.tokens >= 1 {
+				b.tokens--
+				allowed = 1
+			}
+
+			return []any{allowed, strconv.FormatFloat(b.tokens, 'g', 14, 64)}
+		},
+		slidingWindowScript.Src(): func(keys, args []string) any {
+			now := clock.now.UnixMilli()
+			period, _ := strconv.ParseInt(args[0], 10, 64)
+			limit, _ := strconv.ParseInt(args[1], 10, 64)
+			current := now / period
+
+			w, ok := windows[keys[0]]
+			if !ok {
+				w = &window{window: current}
+				windows[keys[0]] = w
+			}
+
+			switch w.window {
+			case current:
+			case current - 1:
+				w.prev, w.curr = w.curr, 0
+			default:
+				w.prev, w.curr = 0, 0
+			}
+
+			w.window = current
+			elapsed := now - current*period
+
+			allowed := 0
+			if float64(w.prev)*float64(period-elapsed)/float64(period)+float64(w.curr)+1 <= float64(limit) {
+				w.curr++
+				allowed = 1
+			}
+
+			return []any{allowed, w.curr, w.prev, elapsed}
+		},
+	})
+
+	srv, err := resptest.NewServer(func(args []string) any {
+		if reply, ok := scripts.Handle(args); ok {
+			return reply
+		}
+
+		return errors.New("ERR unknown command")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewRedisStore(srv.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = store.Close()
+		_ = srv.Close()
+	})
+
+	return store
+}
+
+type take struct {
+	at         time.Duration
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+}
+
+func testStores(t *testing.T, limit Limit, takes []take) {
+	stores := map[string]func(t *testing.T, clock *clock) Store{
+		"memory": func(_ *testing.T, clock *clock) Store { return newMemoryStore(clock) },
+		"redis":  newRedisStore,
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			clock := &clock{now: epoch}
+			store := newStore(t, clock)
+
+			for i, want := range takes {
+				clock.now = epoch.Add(want.at)
+
+				got, err := store.Take(context.Background(), "key", limit)
+				if err != nil {
+					t.Fatalf("take %d: %v", i, err)
+				}
+
+				if got.Allowed != want.allowed || got.Remaining != want.remaining {
+					t.Errorf("take %d at %s = allowed %t, remaining %d, want %t, %d",
+						i, want.at, got.Allowed, got.Remaining, want.allowed, want.remaining)
+				}
+
+				if want.retryAfter > 0 && got.RetryAfter.Round(time.Second) != want.retryAfter {
+					t.Errorf("take %d at %s retry after %s, want %s", i, want.at, got.RetryAfter, want.retryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	testStores(t, Limit{Algorithm: TokenBucket, Requests: 2, Period: time.Minute}, []take{
+		{at: 0, allowed: true, remaining: 1},
+		{at: 0, allowed: true, remaining: 0},
+		{at: 0, allowed: false, remaining: 0, retryAfter: 30 * time.Second},
+		// a token is refilled every 30s, and 40s refill 1.33 of them.
+		{at: 40 * time.Second, allowed: true, remaining: 0},
+		{at: 40 * time.Second, allowed: false, remaining: 0, retryAfter: 20 * time.Second},
+		{at: 10 * time.Minute, allowed: true, remaining: 1},
+	})
+}
+
+func TestTokenBucketBurst(t *testing.T) {
+	testStores(t, Limit{Algorithm: TokenBucket, Requests: 1, Period: time.Minute, Burst: 3}, []take{
+		{at: 0, allowed: true, remaining: 2},
+		{at: 0, allowed: true, remaining: 1},
+		{at: 0, allowed: true, remaining: 0},
+		{at: 0, allowed: false, remaining: 0, retryAfter: time.Minute},
+	})
+}
+
+func TestSlidingWindow(t *testing.T) {
+	testStores(t, Limit{Algorithm: SlidingWindow, Requests: 2, Period: time.Minute}, []take{
+		{at: 0, allowed: true, remaining: 1},
+		{at: 0, allowed: true, remaining: 0},
+		{at: 0, allowed: false, remaining: 0},
+		// 30s into the next window, the 2 requests of the previous one weigh 1.
+		{at: 90 * time.Second, allowed: true, remaining: 0},
+		{at: 90 * time.Second, allowed: false, remaining: 0},
+		{at: 10 * time.Minute, allowed: true, remaining: 1},
+	})
+}
+
+func TestTokenBucketResult(t *testing.T) {
+	limit := Limit{Algorithm: TokenBucket, Requests: 10, Period: 10 * time.Second}
+
+	got := tokenBucket(limit, 2.5, false)
+	want := Result{Allowed: false, Limit: 10, Remaining: 2, Reset: 7500 * time.Millisecond}
+
+	if got.Allowed != want.Allowed || got.Limit != want.Limit || got.Remaining != want.Remaining || got.Reset != want.Reset {
+		t.Errorf("result = %+v, want %+v", got, want)
+	}
+}
+
+func TestSlidingWindowResult(t *testing.T) {
+	limit := Limit{Algorithm: SlidingWindow, Requests: 10, Period: time.Minute}
+
+	got := slidingWindow(limit, 4, 6, 30*time.Second, true)
+	if got.Limit != 10 || got.Remaining != 3 || got.Reset != 90*time.Second {
+		t.Errorf("result = %+v, want limit 10, remaining 3 and reset 1m30s", got)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args  []string
+		limit Limit
+		err   bool
+	}{
+		{args: []string{"100/m"}, limit: Limit{Algorithm: TokenBucket, Requests: 100, Period: time.Minute}},
+		{args: []string{"5/10s", "algorithm=sliding-window"}, limit: Limit{Algorithm: SlidingWindow, Requests: 5, Period: 10 * time.Second}},
+		{args: []string{"100/h", "key=header:X-API-Key", "burst=20"}, limit: Limit{Algorithm: TokenBucket, Requests: 100, Period: time.Hour, Burst: 20}},
+		{args: nil, err: true},
+		{args: []string{"100"}, err: true},
+		{args: []string{"0/m"}, err: true},
+		{args: []string{"100/week"}, err: true},
+		{args: []string{"100/m", "burst=0"}, err: true},
+		{args: []string{"100/m", "key=cookie"}, err: true},
+		{args: []string{"100/m", "algorithm=leaky-bucket"}, err: true},
+		{args: []string{"100/m", "window"}, err: true},
+	}
+
+	for _, tt := range tests {
+		limit, _, err := parseArgs(tt.args)
+		if (err != nil) != tt.err {
+			t.Errorf("parseArgs(%q) error = %v, want error %t", tt.args, err, tt.err)
+
+			continue
+		}
+
+		if limit != tt.limit {
+			t.Errorf("parseArgs(%q) = %+v, want %+v", tt.args, limit, tt.limit)
+		}
+	}
+}
+
+// limited is limited to 2 requests a minute.
+//
+// @ratelimit(2/m)
+// [GET] /limited 200 json
+func limited(_ context.Context, _ *server.Request, _ *struct{}) (map[string]bool, error) {
+	return map[string]bool{"ok": true}, nil
+}
+
+func TestDecorator(t *testing.T) {
+	clock := &clock{now: epoch}
+
+	s := server.New()
+	s.AddDecoratorFactory("ratelimit", Factory(newMemoryStore(clock)))
+	s.Register(server.NewAction(limited))
+
+	client, err := servertest.New(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, remaining := range []string{"1", "0"} {
+		rec := client.GET("/limited").Do()
+		rec.AssertStatus(t, http.StatusOK)
+		rec.AssertHeader(t, "RateLimit-Limit", "2")
+		rec.AssertHeader(t, "RateLimit-Remaining", remaining)
+		rec.AssertHeader(t, "RateLimit-Policy", "2;w=60")
+
+		if i == 0 {
+			rec.AssertHeader(t, "RateLimit-Reset", "30")
+		}
+	}
+
+	rec := client.GET("/limited").Do()
+	rec.AssertStatus(t, http.StatusTooManyRequests)
+	rec.AssertHeader(t, "Retry-After", "30")
+	rec.AssertHeader(t, "RateLimit-Remaining", "0")
+	rec.AssertHeader(t, "RateLimit-Reset", "60")
+
+	clock.now = clock.now.Add(30 * time.Second)
+
+	client.GET("/limited").Do().AssertStatus(t, http.StatusOK)
+}
+
+func TestUnregisteredDecorator(t *testing.T) {
+	s := server.New()
+	s.Register(server.NewAction(limited))
+
+	if err := s.Validate(); err == nil {
+		t.Error("@ratelimit is valid without its factory")
+	}
+}
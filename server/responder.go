@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -15,17 +16,45 @@ func (h *Handler[IN, OUT]) setResponder(name string) {
 		return
 	}
 
+	if h.setNegotiateResponder() {
+		return
+	}
+
+	if h.setSSEResponder(name) {
+		return
+	}
+
 	switch h.ResponderType {
 	case "":
-		h.RespondFn = func(ctx *app.RequestContext, _ any) { ctx.Status(h.Status) }
+		h.RespondFn = func(_ context.Context, ctx *app.RequestContext, _ any) error {
+			ctx.Status(h.status(ctx))
+
+			return nil
+		}
 	case "json":
-		h.RespondFn = func(ctx *app.RequestContext, res any) { ctx.JSON(h.Status, res) }
+		h.RespondFn = func(_ context.Context, ctx *app.RequestContext, res any) error {
+			ctx.JSON(h.status(ctx), res)
+
+			return nil
+		}
 	case "json_pure":
-		h.RespondFn = func(ctx *app.RequestContext, res any) { ctx.PureJSON(h.Status, res) }
+		h.RespondFn = func(_ context.Context, ctx *app.RequestContext, res any) error {
+			ctx.PureJSON(h.status(ctx), res)
+
+			return nil
+		}
 	case "xml":
-		h.RespondFn = func(ctx *app.RequestContext, res any) { ctx.XML(h.Status, res) }
+		h.RespondFn = func(_ context.Context, ctx *app.RequestContext, res any) error {
+			ctx.XML(h.status(ctx), res)
+
+			return nil
+		}
 	case "file":
-		h.RespondFn = func(ctx *app.RequestContext, res any) { ctx.File(fmt.Sprintf("%s", res)) }
+		h.RespondFn = func(_ context.Context, ctx *app.RequestContext, res any) error {
+			ctx.File(fmt.Sprintf("%s", res))
+
+			return nil
+		}
 	case "attachment":
 		h.setAttachmentResponder()
 	case "text":
@@ -38,15 +67,60 @@ func (h *Handler[IN, OUT]) setResponder(name string) {
 		h.setDataResponder()
 	case "render":
 		h.setRenderResponder()
+	case "websocket":
+		h.setWebSocketResponder(name)
 	default:
-		panic(name + " action describer not acceptable")
+		h.invalid("unknown responder %q", h.ResponderType)
 	}
 }
 
+// responseContentTypes returns the content types of the body the responder writes
+// and whether the body is written as raw bytes instead of an encoded response.
+func (r *routeDescriber) responseContentTypes() (contentTypes []string, binary bool) {
+	d := r.apiDescriber
+
+	if strings.Contains(d.ResponderType, "html") || strings.Contains(d.ResponderType, "tmpl") {
+		return []string{"text/html"}, false
+	}
+
+	if names, ok := negotiateEncoders(d.ResponderType); ok {
+		for _, name := range names {
+			if enc, ok := r.server.encoders.get(name); ok {
+				contentTypes = append(contentTypes, mediaType(enc.contentType))
+			}
+		}
+
+		return contentTypes, false
+	}
+
+	if _, ok, _ := sseHeartbeat(d.ResponderType); ok {
+		return []string{"text/event-stream"}, true
+	}
+
+	switch d.ResponderType {
+	case "json", "json_pure":
+		return []string{"application/json"}, false
+	case "xml":
+		return []string{"application/xml"}, false
+	case "text":
+		return []string{"text/plain"}, false
+	case "file":
+		return []string{"application/octet-stream"}, true
+	case "attachment", "stream", "data":
+		return []string{d.ContentType}, true
+	case "render":
+		return []string{"*/*"}, false
+	}
+
+	return nil, false
+}
+
 func (h *Handler[IN, OUT]) setTemplateResponder() bool {
 	if strings.Contains(h.ResponderType, "html") || strings.Contains(h.ResponderType, "tmpl") {
-		h.RespondFn = func(ctx *app.RequestContext, res any) {
-			ctx.HTML(h.Status, h.ResponderType, res)
+		h.RespondFn = func(_ context.Context, ctx *app.RequestContext, res any) error {
+			ctx.HTML(h.status(ctx), h.ResponderType, res)
+
+			return nil
 		}
 
 		return true
@@ -56,50 +130,60 @@ func (h *Handler[IN, OUT]) setTemplateResponder() bool {
 }
 
 func (h *Handler[IN, OUT]) setTextResponder() {
-	h.RespondFn = func(ctx *app.RequestContext, res any) {
+	h.RespondFn = func(_ context.Context, ctx *app.RequestContext, res any) error {
+		ctx.Status(h.status(ctx))
+
 		_, err := ctx.WriteString(fmt.Sprintf("%s", res))
-		if err != nil {
-			panic(err)
-		}
+
+		return err
 	}
 }
 
 func (h *Handler[IN, OUT]) setRedirectResponder() {
-	h.RespondFn = func(ctx *app.RequestContext, res any) {
-		ctx.Redirect(h.Status, []byte(fmt.Sprintf("%v", res)))
+	h.RespondFn = func(_ context.Context, ctx *app.RequestContext, res any) error {
+		ctx.Redirect(h.status(ctx), []byte(fmt.Sprintf("%v", res)))
+
+		return nil
 	}
 }
 
 func (h *Handler[IN, OUT]) setAttachmentResponder() {
-	h.RespondFn = func(ctx *app.RequestContext, res any) {
+	h.RespondFn = func(_ context.Context, ctx *app.RequestContext, res any) error {
 		filepath := fmt.Sprintf("%s", res)
 		filename := strings.Split(filepath, "/")
 
 		ctx.SetContentType(h.ContentType)
 		ctx.FileAttachment(filepath, filename[len(filename)-1])
+
+		return nil
 	}
 }
 
 func (h *Handler[IN, OUT]) setStreamResponder() {
-	h.RespondFn = func(ctx *app.RequestContext, res any) {
+	h.RespondFn = func(_ context.Context, ctx *app.RequestContext, res any) error {
 		ctx.SetContentType(h.ContentType)
+		ctx.Status(h.status(ctx))
 
 		reader := bytes.NewReader(reflect.ValueOf(res).Bytes())
-		if _, err := reader.WriteTo(ctx.Response.BodyWriter()); err != nil {
-			panic(err)
-		}
+		_, err := reader.WriteTo(ctx.Response.BodyWriter())
+
+		return err
 	}
 }
 
 func (h *Handler[IN, OUT]) setDataResponder() {
-	h.RespondFn = func(ctx *app.RequestContext, res any) {
+	h.RespondFn = func(_ context.Context, ctx *app.RequestContext, res any) error {
 		ctx.SetContentType(h.ContentType)
-		ctx.Data(h.Status, h.ContentType, reflect.ValueOf(res).Bytes())
+		ctx.Data(h.status(ctx), h.ContentType, reflect.ValueOf(res).Bytes())
+
+		return nil
 	}
 }
 
 func (h *Handler[IN, OUT]) setRenderResponder() {
-	h.RespondFn = func(ctx *app.RequestContext, res any) {
-		ctx.Render(h.Status, res.(render.Render))
+	h.RespondFn = func(_ context.Context, ctx *app.RequestContext, res any) error {
+		ctx.Render(h.status(ctx), res.(render.Render))
+
+		return nil
 	}
 }
@@ -0,0 +1,373 @@
+package server
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// Status is the status of a response, when it is a field of an OUT struct. The zero
+// Status keeps the status of the describer.
+//
+//	type CreateUserResponse struct {
+//		Status   server.Status `json:"-"`
+//		Location string        `header:"Location"`
+//		Session  *http.Cookie  `cookie:"session"`
+//		User     *User         `json:"user"`
+//	}
+//
+// The Status, header and cookie fields, including those of embedded structs, are set
+// on the response and left out of its body by the json, xml and negotiate responders.
+// The other responders get the OUT value itself. The OUT type of an encoding responder
+// cannot have them if it has a MarshalJSON or MarshalXML method, which writes the body.
+// A header field is a string, a fmt.Stringer, a TextMarshaler, a time.Time or a slice
+// of them, and a cookie field is a string value or an *http.Cookie.
+type Status int
+
+// StatusCoder is implemented by the OUT values choosing the status of their response.
+// It takes precedence over a Status field. A zero status keeps the status of the describer.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+const statusKey = "server.status"
+
+var (
+	statusType      = reflect.TypeOf(Status(0))
+	cookieType      = reflect.TypeOf((*http.Cookie)(nil))
+	jsonMarshaler   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	xmlMarshaler    = reflect.TypeOf((*xml.Marshaler)(nil)).Elem()
+	textMarshaler   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	statusCoderType = reflect.TypeOf((*StatusCoder)(nil)).Elem()
+)
+
+// responseMeta holds the response fields of an OUT struct and its body type without them.
+// The fields of the embedded structs are promoted, as encoding/json does.
+type responseMeta struct {
+	status  [][]int
+	headers []metaField
+	cookies []metaField
+	// body is the struct of the other fields, and fields are their indexes in OUT.
+	body   reflect.Type
+	fields [][]int
+	coder  bool
+}
+
+type metaField struct {
+	index []int
+	name  string
+}
+
+// bodyField is a field of the body, found depth embedded structs deep in OUT.
+type bodyField struct {
+	reflect.StructField
+	depth int
+}
+
+// responseMetaOf returns the response fields of out, or nil if it has none. If the body
+// is encoded from the fields of out, the meta has the type of the body without them,
+// and it returns an error if out marshals itself, since its marshaler would write them.
+func responseMetaOf(out reflect.Type, encoded bool) (*responseMeta, error) {
+	coder := out.Implements(statusCoderType)
+
+	for out.Kind() == reflect.Pointer {
+		out = out.Elem()
+	}
+
+	if out.Kind() != reflect.Struct {
+		if coder {
+			return &responseMeta{coder: true}, nil
+		}
+
+		return nil, nil
+	}
+
+	meta := &responseMeta{coder: coder}
+
+	var fields []bodyField
+	meta.walk(out, nil, &fields)
+
+	if len(meta.status)+len(meta.headers)+len(meta.cookies) == 0 {
+		if coder {
+			return &responseMeta{coder: true}, nil
+		}
+
+		return nil, nil
+	}
+
+	if !encoded {
+		return meta, nil
+	}
+
+	if ptr := reflect.PointerTo(out); ptr.Implements(jsonMarshaler) || ptr.Implements(xmlMarshaler) {
+		return nil, fmt.Errorf("%s has Status, header or cookie fields and marshals itself, which would write them in the body", out)
+	}
+
+	bodyFields := make([]reflect.StructField, 0, len(fields))
+
+	// the body fields are named by their embedded types, which may have methods StructOf rejects.
+	for _, field := range promoted(fields) {
+		meta.fields = append(meta.fields, field.Index)
+
+		field.Index, field.Offset, field.Anonymous = nil, 0, false
+		bodyFields = append(bodyFields, field)
+	}
+
+	meta.body = bodyType(out, bodyFields)
+
+	return meta, nil
+}
+
+// walk adds the response and body fields of t, whose index in OUT is index, and
+// the fields of the structs it embeds without a json name.
+func (m *responseMeta) walk(t reflect.Type, index []int, fields *[]bodyField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		field.Index = append(slices.Clone(index), i)
+
+		switch name := field.Tag.Get("header"); {
+		case field.Type == statusType:
+			m.status = append(m.status, field.Index)
+		case name != "" && name != "-":
+			m.headers = append(m.headers, metaField{index: field.Index, name: name})
+		case field.Tag.Get("cookie") != "" && field.Tag.Get("cookie") != "-":
+			m.cookies = append(m.cookies, metaField{index: field.Index, name: field.Tag.Get("cookie")})
+		case embedded(field):
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			m.walk(ft, field.Index, fields)
+		case field.IsExported():
+			*fields = append(*fields, bodyField{StructField: field, depth: len(index)})
+		}
+	}
+}
+
+// embedded reports whether the fields of an embedded struct are promoted to the
+// object of its parent in JSON.
+func embedded(field reflect.StructField) bool {
+	if !field.Anonymous {
+		return false
+	}
+
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return false
+	}
+
+	ft := field.Type
+	if ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+
+	return ft.Kind() == reflect.Struct
+}
+
+// promoted returns the fields which are not hidden by a shallower field of the same
+// name, dropping the ambiguous fields of the same name and depth, as Go does.
+func promoted(fields []bodyField) []reflect.StructField {
+	shallowest := make(map[string]int)
+	count := make(map[string]int)
+
+	for _, field := range fields {
+		switch depth, ok := shallowest[field.Name]; {
+		case !ok || field.depth < depth:
+			shallowest[field.Name], count[field.Name] = field.depth, 1
+		case field.depth == depth:
+			count[field.Name]++
+		}
+	}
+
+	result := make([]reflect.StructField, 0, len(fields))
+
+	for _, field := range fields {
+		if field.depth == shallowest[field.Name] && count[field.Name] == 1 {
+			result = append(result, field.StructField)
+		}
+	}
+
+	return result
+}
+
+// bodyType creates the struct of the body fields of out, named as out in XML.
+func bodyType(out reflect.Type, fields []reflect.StructField) reflect.Type {
+	if _, ok := out.FieldByName("XMLName"); !ok {
+		fields = append([]reflect.StructField{{
+			Name: "XMLName",
+			Type: reflect.TypeOf(xml.Name{}),
+			Tag:  reflect.StructTag(`json:"-" xml:"` + out.Name() + `"`),
+		}}, fields...)
+	}
+
+	return reflect.StructOf(fields)
+}
+
+// apply sets the status, headers and cookies of res on the response, and returns
+// the body to respond.
+func (m *responseMeta) apply(rctx *app.RequestContext, res any) (any, error) {
+	v := reflect.ValueOf(res)
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return res, nil
+	}
+
+	if coder, ok := res.(StatusCoder); ok && m.coder {
+		if status := coder.StatusCode(); status != 0 {
+			rctx.Set(statusKey, status)
+		}
+	}
+
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return res, nil
+	}
+
+	if _, ok := rctx.Get(statusKey); !ok {
+		for _, index := range m.status {
+			if field, err := v.FieldByIndexErr(index); err == nil && field.Int() != 0 {
+				rctx.Set(statusKey, int(field.Int()))
+			}
+		}
+	}
+
+	for _, header := range m.headers {
+		field, err := v.FieldByIndexErr(header.index)
+		if err != nil {
+			continue
+		}
+
+		values, err := headerValues(field)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", header.name, err)
+		}
+
+		for _, value := range values {
+			rctx.Response.Header.Add(header.name, value)
+		}
+	}
+
+	for _, cookie := range m.cookies {
+		field, err := v.FieldByIndexErr(cookie.index)
+		if err != nil {
+			continue
+		}
+
+		if err := setCookie(rctx, cookie.name, field); err != nil {
+			return nil, fmt.Errorf("cookie %s: %w", cookie.name, err)
+		}
+	}
+
+	if m.body == nil {
+		return res, nil
+	}
+
+	body := reflect.New(m.body).Elem()
+	offset := m.body.NumField() - len(m.fields)
+
+	// the fields of a nil embedded struct are left zero.
+	for i, index := range m.fields {
+		if field, err := v.FieldByIndexErr(index); err == nil {
+			body.Field(offset + i).Set(field)
+		}
+	}
+
+	return body.Addr().Interface(), nil
+}
+
+// headerValues formats the value of a header field, none if it is zero.
+func headerValues(v reflect.Value) ([]string, error) {
+	if v.IsZero() {
+		return nil, nil
+	}
+
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		values := make([]string, 0, v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			value, err := headerValues(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, value...)
+		}
+
+		return values, nil
+	}
+
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return []string{value.UTC().Format(http.TimeFormat)}, nil
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+
+		return []string{string(text)}, err
+	case fmt.Stringer:
+		return []string{value.String()}, nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textMarshaler) {
+		text, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+
+		return []string{string(text)}, err
+	}
+
+	return []string{fmt.Sprint(v.Interface())}, nil
+}
+
+// setCookie sets a cookie field, a string value with the Path / or an *http.Cookie.
+func setCookie(rctx *app.RequestContext, name string, v reflect.Value) error {
+	if v.IsZero() {
+		return nil
+	}
+
+	c := &http.Cookie{Name: name, Path: "/"}
+
+	switch {
+	case v.Type() == cookieType:
+		*c = *v.Interface().(*http.Cookie)
+		if c.Name == "" {
+			c.Name = name
+		}
+	case v.Kind() == reflect.String:
+		c.Value = v.String()
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	cookie := protocol.AcquireCookie()
+	defer protocol.ReleaseCookie(cookie)
+
+	if err := cookie.Parse(c.String()); err != nil {
+		return err
+	}
+
+	rctx.Response.Header.SetCookie(cookie)
+
+	return nil
+}
+
+// status returns the status of the response, set by the OUT value or by the describer.
+func (h *Handler[IN, OUT]) status(ctx *app.RequestContext) int {
+	if status, ok := ctx.Value(statusKey).(int); ok {
+		return status
+	}
+
+	return h.Status
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+type created struct {
+	Status   Status `json:"-"`
+	Location string `header:"Location"`
+	Name     string `json:"name" xml:"name"`
+}
+
+type Meta struct {
+	RequestID string `header:"X-Request-Id"`
+	Version   int    `json:"version"`
+}
+
+type createdUser struct {
+	Meta
+	Name string `json:"name"`
+}
+
+// createdJSON has its response fields left out of its json body.
+//
+// [POST] /created/json 200 json
+func createdJSON(_ context.Context, _ *Request, _ *struct{}) (*created, error) {
+	return &created{Status: http.StatusCreated, Location: "/users/1", Name: "ann"}, nil
+}
+
+// createdXML has its response fields left out of its xml body.
+//
+// [POST] /created/xml 200 xml
+func createdXML(_ context.Context, _ *Request, _ *struct{}) (*created, error) {
+	return &created{Status: http.StatusCreated, Location: "/users/1", Name: "ann"}, nil
+}
+
+// createdNegotiated has its response fields left out of its negotiated body.
+//
+// [POST] /created/negotiated 200 negotiate(json)
+func createdNegotiated(_ context.Context, _ *Request, _ *struct{}) (*created, error) {
+	return &created{Status: http.StatusCreated, Location: "/users/1", Name: "ann"}, nil
+}
+
+// createdEmbedded promotes the fields of its embedded struct.
+//
+// [POST] /created/embedded 201 json
+func createdEmbedded(_ context.Context, _ *Request, _ *struct{}) (*createdUser, error) {
+	return &createdUser{Meta: Meta{RequestID: "r1", Version: 2}, Name: "ann"}, nil
+}
+
+type greeting struct {
+	Language string `header:"Content-Language"`
+	Name     string
+}
+
+func (g greeting) String() string {
+	return "hello " + g.Name
+}
+
+// greetText writes the String of its OUT.
+//
+// [GET] /greet/text 200 text
+func greetText(_ context.Context, _ *Request, _ *struct{}) (greeting, error) {
+	return greeting{Language: "en", Name: "ann"}, nil
+}
+
+type moved struct {
+	Status   Status
+	Reason   string `header:"X-Reason"`
+	Location string
+}
+
+func (m moved) String() string {
+	return m.Location
+}
+
+// redirectMoved redirects to the String of its OUT.
+//
+// [GET] /moved 302 redirect
+func redirectMoved(_ context.Context, _ *Request, _ *struct{}) (moved, error) {
+	return moved{Status: http.StatusMovedPermanently, Reason: "renamed", Location: "/new"}, nil
+}
+
+type banner struct {
+	Language string `header:"Content-Language"`
+	Text     string
+}
+
+func (b *banner) Render(resp *protocol.Response) error {
+	b.WriteContentType(resp)
+	resp.SetBodyString(strings.ToUpper(b.Text))
+
+	return nil
+}
+
+func (b *banner) WriteContentType(resp *protocol.Response) {
+	resp.Header.SetContentType("text/plain; charset=utf-8")
+}
+
+// renderBanner is rendered by its OUT.
+//
+// [GET] /banner 200 render
+func renderBanner(_ context.Context, _ *Request, _ *struct{}) (*banner, error) {
+	return &banner{Language: "en", Text: "welcome"}, nil
+}
+
+var bannerFile string
+
+type download struct {
+	Disposition string `header:"X-Download"`
+	Path        string
+}
+
+func (d download) String() string {
+	return d.Path
+}
+
+// serveDownload serves the file of the String of its OUT.
+//
+// [GET] /download 200 file
+func serveDownload(_ context.Context, _ *Request, _ *struct{}) (download, error) {
+	return download{Disposition: "inline", Path: bannerFile}, nil
+}
+
+type payload []byte
+
+func (payload) StatusCode() int {
+	return http.StatusAccepted
+}
+
+// sendData writes its bytes with the status of its OUT.
+//
+// [GET] /data 200 data@application/octet-stream
+func sendData(_ context.Context, _ *Request, _ *struct{}) (payload, error) {
+	return payload("raw"), nil
+}
+
+func TestResponseFields(t *testing.T) {
+	bannerFile = filepath.Join(t.TempDir(), "banner.txt")
+	if err := os.WriteFile(bannerFile, []byte("from a file"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New()
+	s.Register(
+		NewAction(createdJSON), NewAction(createdXML), NewAction(createdNegotiated), NewAction(createdEmbedded),
+		NewAction(greetText), NewAction(redirectMoved), NewAction(renderBanner), NewAction(serveDownload), NewAction(sendData),
+	)
+
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := s.Build().Engine
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		header [2]string
+		body   string
+	}{
+		{method: http.MethodPost, path: "/created/json", status: http.StatusCreated, header: [2]string{"Location", "/users/1"}, body: `{"name":"ann"}`},
+		{method: http.MethodPost, path: "/created/xml", status: http.StatusCreated, header: [2]string{"Location", "/users/1"}, body: `<created><name>ann</name></created>`},
+		{method: http.MethodPost, path: "/created/negotiated", status: http.StatusCreated, header: [2]string{"Location", "/users/1"}, body: `{"name":"ann"}`},
+		{method: http.MethodPost, path: "/created/embedded", status: http.StatusCreated, header: [2]string{"X-Request-Id", "r1"}, body: `{"version":2,"name":"ann"}`},
+		{method: http.MethodGet, path: "/greet/text", status: http.StatusOK, header: [2]string{"Content-Language", "en"}, body: "hello ann"},
+		{method: http.MethodGet, path: "/moved", status: http.StatusMovedPermanently, header: [2]string{"Location", "/new"}},
+		{method: http.MethodGet, path: "/banner", status: http.StatusOK, header: [2]string{"Content-Language", "en"}, body: "WELCOME"},
+		{method: http.MethodGet, path: "/download", status: http.StatusOK, header: [2]string{"X-Download", "inline"}, body: "from a file"},
+		{method: http.MethodGet, path: "/data", status: http.StatusAccepted, body: "raw"},
+	}
+
+	for _, tt := range tests {
+		res := ut.PerformRequest(engine, tt.method, tt.path, nil)
+
+		if res.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.path, res.Code, tt.status, res.Body.String())
+		}
+
+		if tt.header[0] != "" && res.Header().Get(tt.header[0]) != tt.header[1] {
+			t.Errorf("%s: %s = %q, want %q", tt.path, tt.header[0], res.Header().Get(tt.header[0]), tt.header[1])
+		}
+
+		if body := strings.TrimSpace(res.Body.String()); tt.body != "" && body != tt.body {
+			t.Errorf("%s: body = %s, want %s", tt.path, body, tt.body)
+		}
+	}
+}
+
+type selfMarshaled struct {
+	Location string `header:"Location"`
+}
+
+func (selfMarshaled) MarshalJSON() ([]byte, error) {
+	return json.Marshal("self")
+}
+
+// marshaledJSON marshals itself, which would write its response fields.
+//
+// [GET] /marshaled/json 200 json
+func marshaledJSON(_ context.Context, _ *Request, _ *struct{}) (selfMarshaled, error) {
+	return selfMarshaled{}, nil
+}
+
+// marshaledText marshals itself, which the text responder does not call.
+//
+// [GET] /marshaled/text 200 text
+func marshaledText(_ context.Context, _ *Request, _ *struct{}) (selfMarshaled, error) {
+	return selfMarshaled{Location: "/here"}, nil
+}
+
+func TestResponseFieldsMarshaler(t *testing.T) {
+	s := New()
+	s.Register(NewAction(marshaledJSON), NewAction(marshaledText))
+
+	err := s.Validate()
+	if err == nil || !strings.Contains(err.Error(), "marshals itself") {
+		t.Fatalf("Validate() = %v, want the self-marshaling json OUT", err)
+	}
+
+	if strings.Contains(err.Error(), "marshaledText") {
+		t.Errorf("Validate() = %v, want the text responder accepted", err)
+	}
+}
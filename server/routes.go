@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const routesPath = "/_routes"
+
+// RouteInfo describes a registered route, as listed by Routes.
+type RouteInfo struct {
+	Verb   string `json:"verb"`
+	Path   string `json:"path"`
+	Status int    `json:"status"`
+	// Responder is the responder type of the describer, such as json or sse(10s).
+	Responder string `json:"responder"`
+	// ContentType is the content types of the response body, separated by commas.
+	ContentType string `json:"contentType,omitempty"`
+	Function    string `json:"function"`
+	In          string `json:"in,omitempty"`
+	Out         string `json:"out,omitempty"`
+	// Authenticated reports the route requires an identity, and Authorize is the
+	// expression the identity must satisfy, empty for any identity.
+	Authenticated bool     `json:"authenticated"`
+	Authorize     string   `json:"authorize,omitempty"`
+	Roles         []string `json:"roles,omitempty"`
+	Permissions   []string `json:"permissions,omitempty"`
+	Policies      []string `json:"policies,omitempty"`
+	// Decorators are the decorator directives of the groups of the route and of the route.
+	Decorators []string `json:"decorators,omitempty"`
+	File       string   `json:"file,omitempty"`
+	Line       int      `json:"line,omitempty"`
+}
+
+// Routes returns the routes registered in the default Server.
+func Routes() []RouteInfo {
+	return defaultServer.Routes()
+}
+
+// ServeRoutes serves the table of Routes on /_routes to the identities authorized by
+// the `@authorize` expression, or to any identity if it is empty. The table is JSON,
+// or text if the request accepts text/plain or has the format=text query.
+func ServeRoutes(authorize string) {
+	defaultServer.ServeRoutes(authorize)
+}
+
+// Routes returns the registered routes ordered by path and verb.
+func (s *Server) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(s.routes))
+
+	for _, r := range s.routes {
+		if r.described() {
+			routes = append(routes, r.info())
+		}
+	}
+
+	slices.SortStableFunc(routes, func(a, b RouteInfo) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Verb, b.Verb)
+	})
+
+	return routes
+}
+
+// ServeRoutes serves the table of Routes on /_routes to the identities authorized by
+// the `@authorize` expression, or to any identity if it is empty. It panics if the
+// expression is invalid.
+//
+//	s.ServeRoutes("role:admin || role:ops")
+func (s *Server) ServeRoutes(authorize string) {
+	describer, err := parseAuthorize(authorize)
+	if err != nil {
+		panic(fmt.Sprintf("%s: %v", routesPath, err))
+	}
+
+	s.routesAuthorize = describer
+}
+
+func (r *routeDescriber) info() RouteInfo {
+	contentTypes, _ := r.responseContentTypes()
+
+	info := RouteInfo{
+		Verb:        r.Verb,
+		Path:        r.Path,
+		Status:      r.Status,
+		Responder:   r.ResponderType,
+		ContentType: strings.Join(contentTypes, ", "),
+		Function:    r.FunctionName,
+		Policies:    r.Policies,
+		Decorators:  r.Decorators,
+		File:        r.File,
+		Line:        r.Line,
+	}
+
+	if r.In != nil {
+		info.In = r.In.String()
+	}
+
+	if r.Out != nil {
+		info.Out = r.Out.String()
+	}
+
+	if !r.hasGroup() {
+		return info
+	}
+
+	info.Path = r.FullPath()
+	info.Decorators = slices.Concat(r.routerGroup().allDecorators(), r.Decorators)
+
+	if describer := r.authorization(); describer != nil {
+		info.Authenticated = true
+		info.Authorize = describer.Expr.String()
+		info.Roles = describer.Roles
+		info.Permissions = describer.Permissions
+	}
+
+	return info
+}
+
+var routesContentTypes = []string{"application/json", "text/plain"}
+
+func (s *Server) routesHandler() app.HandlerFunc {
+	routes := sync.OnceValue(s.Routes)
+
+	return func(_ context.Context, rctx *app.RequestContext) {
+		format := rctx.Query("format")
+		if format == "" && negotiate(string(rctx.GetHeader("Accept")), routesContentTypes) == 1 {
+			format = "text"
+		}
+
+		if format == "text" {
+			rctx.Data(http.StatusOK, "text/plain; charset=utf-8", routesTable(routes()))
+
+			return
+		}
+
+		rctx.JSON(http.StatusOK, routes())
+	}
+}
+
+// routesTable renders the routes as a text table.
+func routesTable(routes []RouteInfo) []byte {
+	var b strings.Builder
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERB\tPATH\tSTATUS\tRESPONDER\tAUTHORIZE\tPOLICIES\tDECORATORS\tFUNCTION\tSOURCE")
+
+	for _, r := range routes {
+		authorize := "-"
+		if r.Authenticated {
+			authorize = r.Authorize
+			if authorize == "" {
+				authorize = "any identity"
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Verb, r.Path, r.Status, r.Responder, authorize,
+			orDash(strings.Join(r.Policies, ", ")), orDash(strings.Join(r.Decorators, " ")),
+			r.Function, r.File+":"+strconv.Itoa(r.Line))
+	}
+
+	_ = w.Flush()
+
+	return []byte(b.String())
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
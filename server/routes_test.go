@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+// identifyByRole identifies the requests with an X-Role header by their role.
+func identifyByRole(_ context.Context, req *Request, _ []string, _ ...string) {
+	if role := string(req.GetHeader("X-Role")); role != "" {
+		req.SetIdentity(Identity{ID: "1", Role: role})
+	}
+}
+
+// routesServer serves getOrder, authorizedExpr and tagged.
+func routesServer() *Server {
+	s := New()
+	s.SetIdentifier(identifyByRole)
+	s.AddPolicy("ownsOrder", Policy(func(context.Context, *Request, *getOrderRequest) error { return nil }))
+	s.AddDecorator("logged", func(c context.Context, req *Request) { req.Next(c) })
+	s.AddDecoratorFactory("tag", func([]string) (DecoratorFunc, error) {
+		return func(c context.Context, req *Request) { req.Next(c) }, nil
+	})
+	s.Register(NewAction(tagged), NewAction(getOrder), NewAction(authorizedExpr))
+
+	return s
+}
+
+func TestRoutes(t *testing.T) {
+	routes := routesServer().Routes()
+
+	paths := make([]string, 0, len(routes))
+	for _, r := range routes {
+		paths = append(paths, r.Verb+" "+r.Path)
+	}
+
+	if want := []string{"GET /expr", "GET /orders/:id", "GET /tagged"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("routes = %v, want %v", paths, want)
+	}
+
+	order := routes[1]
+	if order.Line == 0 {
+		t.Errorf("line of getOrder is not set")
+	}
+
+	order.File, order.Line = filepath.Base(order.File), 0
+
+	want := RouteInfo{
+		Verb: http.MethodGet, Path: "/orders/:id", Status: http.StatusOK, Responder: "json", ContentType: "application/json",
+		Function: order.Function, In: "*server.getOrderRequest", Out: "map[string]string",
+		Authenticated: true, Policies: []string{"ownsOrder"}, File: "policy_test.go",
+	}
+	if !strings.HasSuffix(order.Function, ".getOrder") || !reflect.DeepEqual(order, want) {
+		t.Errorf("getOrder = %+v, want %+v", order, want)
+	}
+
+	if expr := routes[0]; expr.Authorize != "role:admin || perm:orders.read" {
+		t.Errorf("authorize of authorizedExpr = %q", expr.Authorize)
+	}
+
+	if decorators := routes[2].Decorators; !reflect.DeepEqual(decorators, []string{"tag(a, b)", "logged"}) {
+		t.Errorf("decorators of tagged = %q", decorators)
+	}
+}
+
+func TestServeRoutes(t *testing.T) {
+	s := routesServer()
+	s.ServeRoutes("role:admin")
+
+	engine := s.Build().Engine
+	get := func(path string, headers ...ut.Header) *ut.ResponseRecorder {
+		return ut.PerformRequest(engine, http.MethodGet, path, nil, headers...)
+	}
+	admin := ut.Header{Key: "X-Role", Value: "admin"}
+
+	if res := get(routesPath); res.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous = %d, want 401", res.Code)
+	}
+
+	if res := get(routesPath, ut.Header{Key: "X-Role", Value: "guest"}); res.Code != http.StatusForbidden {
+		t.Errorf("guest = %d, want 403", res.Code)
+	}
+
+	res := get(routesPath, admin)
+
+	var routes []RouteInfo
+	if err := json.Unmarshal(res.Body.Bytes(), &routes); err != nil || len(routes) != 3 || routes[1].Path != "/orders/:id" {
+		t.Errorf("json = %d %s", res.Code, res.Body.String())
+	}
+
+	for _, res := range []*ut.ResponseRecorder{
+		get(routesPath, admin, ut.Header{Key: "Accept", Value: "text/plain"}),
+		get(routesPath+"?format=text", admin),
+	} {
+		lines := strings.Split(res.Body.String(), "\n")
+		if !strings.HasPrefix(res.Header().Get("Content-Type"), "text/plain") || len(lines) != 5 ||
+			!strings.HasPrefix(lines[0], "VERB") || !strings.Contains(lines[2], "any identity") || !strings.Contains(lines[2], "ownsOrder") {
+			t.Errorf("text = %q", res.Body.String())
+		}
+	}
+}
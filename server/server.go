@@ -5,73 +5,257 @@ import (
 	"fmt"
 	"net/http"
 	"runtime/debug"
-	"strings"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/config"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/maadiii/hertz/server/openapi"
 )
 
-var (
-	s                *server.Hertz
-	handleError      ErrorHandler
-	uses             = make([]app.HandlerFunc, 0)
-	static           = make(map[string]string)
-	staticFile       = make(map[string]string)
-	handlersMap      = make(map[string][]app.HandlerFunc, 0)
-	noRouteHandlers  = make([]app.HandlerFunc, 0)
-	noMethodHandlers = make([]app.HandlerFunc, 0)
-)
+// Server holds the handlers, middlewares and settings of a hertz server.
+// The package level functions use a default Server.
+type Server struct {
+	opts               []config.Option
+	handleError        ErrorHandler
+	identifier         identifierFn
+	decorators         map[string]decoratorFn
+	decoratorFactories map[string]decoratorFactory
+	policies           map[string]policyFn
+	encoders           *encoderSet
+	cache              CacheBackend
+	idempotency        IdempotencyStore
+	defaultTimeout     time.Duration
+	strictPathParams   bool
+	uses               []app.HandlerFunc
+	static             map[string]string
+	staticFile         map[string]string
+	noRouteHandlers    []app.HandlerFunc
+	noMethodHandlers   []app.HandlerFunc
+	root               *RouterGroup
+	groups             map[string]*RouterGroup
+	duplicateGroups    []string
+	routes             []*routeDescriber
+	openAPIInfo        *openapi.Info
+	swaggerUIPath      string
+	swaggerUIAssets    string
+	routesAuthorize    *identifierDescriber
+	resume             ResumeFn
+	webSocket          WebSocketConfig
+	webSockets         webSockets
+	lifecycle          lifecycle
+	health             health
+}
+
+const serverKey = "server"
+
+// serverOf returns the Server handling the request.
+func serverOf(rctx *app.RequestContext) *Server {
+	s, _ := rctx.Value(serverKey).(*Server)
+
+	return s
+}
+
+// serverOrDefault returns the Server handling the request, or the default Server if
+// the request is not served by a built Server, as by the handlers of a route mounted
+// on another engine.
+func serverOrDefault(rctx *app.RequestContext) *Server {
+	if s := serverOf(rctx); s != nil {
+		return s
+	}
+
+	return defaultServer
+}
+
+var defaultServer = New()
+
+// New creates a Server built with the given hertz options, and the options of the
+// Server itself, as WithHealth.
+func New(opts ...config.Option) *Server {
+	s := &Server{
+		handleError:        ProblemErrorHandler,
+		decorators:         make(map[string]decoratorFn),
+		decoratorFactories: make(map[string]decoratorFactory),
+		policies:           make(map[string]policyFn),
+		encoders:           newEncoderSet(),
+		cache:              NewLRUCache(defaultCacheCapacity),
+		idempotency:        NewMemoryIdempotencyStore(),
+		uses:               make([]app.HandlerFunc, 0),
+		static:             make(map[string]string),
+		staticFile:         make(map[string]string),
+		noRouteHandlers:    make([]app.HandlerFunc, 0),
+		noMethodHandlers:   make([]app.HandlerFunc, 0),
+		groups:             make(map[string]*RouterGroup),
+		routes:             make([]*routeDescriber, 0),
+	}
+	s.root = &RouterGroup{server: s}
+	s.decoratorFactories["cache"] = s.cacheDecorator
+	s.decoratorFactories["idempotent"] = s.idempotentDecorator
+	s.opts = opts
+	s.apply(opts)
+
+	return s
+}
 
+// applying holds the Servers which the options are applied to, by the config.Options
+// they are applied through.
+var applying sync.Map
+
+// serverOption is an option of the Server itself, which the hertz server ignores.
+func serverOption(f func(s *Server)) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		if s, ok := applying.Load(o); ok {
+			f(s.(*Server))
+		}
+	}}
+}
+
+// apply applies the options of the Server, which the hertz server ignores.
+func (s *Server) apply(opts []config.Option) {
+	o := new(config.Options)
+
+	applying.Store(o, s)
+	defer applying.Delete(o)
+
+	o.Apply(opts)
+}
+
+// Default returns the Server used by the package level functions.
+func Default() *Server {
+	return defaultServer
+}
+
+// Hertz builds the default Server with the given options. The hertz options only
+// apply to the built server, while the options of the Server, as WithHealth, are set
+// on the default Server.
 func Hertz(opts ...config.Option) *server.Hertz {
+	defaultServer.apply(opts)
+
+	return defaultServer.build(slices.Concat(defaultServer.opts, opts))
+}
+
+// Build creates the hertz server and mounts the registered handlers on it.
+// It panics with the *ValidationError of Validate if the routes are invalid.
+func (s *Server) Build() *server.Hertz {
+	return s.build(s.opts)
+}
+
+// build creates the hertz server with the hertz options opts.
+func (s *Server) build(opts []config.Option) *server.Hertz {
+	if err := s.Validate(); err != nil {
+		panic(err)
+	}
+
 	hlog.SetLevel(hlog.Level(7))
-	s = server.New(opts...)
+	h := server.New(opts...)
 
-	for i := range uses {
-		s.Use(uses[i])
+	for i := range s.uses {
+		h.Use(s.uses[i])
 	}
 
-	s.Use(func(c context.Context, ctx *app.RequestContext) {
+	h.Use(func(c context.Context, ctx *app.RequestContext) {
+		ctx.Set(serverKey, s)
+
 		defer func() {
 			if r := recover(); r != nil {
 				err := fmt.Errorf("%v\n%v", r, string(debug.Stack()))
 				_ = ctx.Error(ctx.AbortWithError(http.StatusInternalServerError, err))
-				handleError(c, ctx, err)
+				s.fail(c, ctx, err)
 			}
 		}()
 
 		ctx.Next(c)
 	})
 
-	for relativePath, root := range static {
-		s.Static(relativePath, root)
+	for relativePath, root := range s.static {
+		h.Static(relativePath, root)
 	}
 
-	for relativePath, filePath := range staticFile {
-		s.StaticFile(relativePath, filePath)
+	for relativePath, filePath := range s.staticFile {
+		h.StaticFile(relativePath, filePath)
 	}
 
-	s.NoMethod(noMethodHandlers...)
-	s.NoRoute(noRouteHandlers...)
+	for relativePath, handler := range s.openAPIHandlers() {
+		h.GET(relativePath, handler)
+	}
 
-	for key, handlers := range handlersMap {
-		verbAndPath := strings.Split(key, "::")
+	for relativePath, handler := range s.healthHandlers() {
+		h.GET(relativePath, handler)
+	}
 
-		s.Handle(verbAndPath[0], verbAndPath[1], handlers...)
+	if s.routesAuthorize != nil {
+		h.GET(routesPath, s.identify(s.routesAuthorize), s.routesHandler())
 	}
 
-	return s
+	if s.health.serve {
+		h.SetCustomSignalWaiter(s.waitSignal)
+	}
+
+	h.OnRun = append(h.OnRun, s.lifecycle.run)
+	h.OnShutdown = append(h.OnShutdown, s.shutdown)
+
+	h.NoMethod(s.noMethodHandlers...)
+	h.NoRoute(s.noRouteHandlers...)
+
+	mounted := map[*RouterGroup]*route.RouterGroup{s.root: &h.RouterGroup}
+	for _, r := range s.routes {
+		r.routerGroup().mount(mounted).Handle(r.Verb, r.Path, r.handlers()...)
+	}
+
+	return h
+}
+
+// NoMethod sets the handlers called when the HTTP method does not match.
+func (s *Server) NoMethod(handlers ...app.HandlerFunc) {
+	s.noMethodHandlers = append(s.noMethodHandlers, handlers...)
+}
+
+// NoRoute adds handlers for NoRoute. It returns a 404 code by default.
+func (s *Server) NoRoute(handlers ...app.HandlerFunc) {
+	s.noRouteHandlers = append(s.noRouteHandlers, handlers...)
+}
+
+// Static serves files from the given file system root.
+// To use the operating system's file system implementation,
+// use :
+//
+//	router.Static("/static", "/var/www")
+func (s *Server) Static(relativePath, root string) {
+	s.static[relativePath] = root
+}
+
+// StaticFile registers a single route in order to Serve a single file of the local filesystem.
+// router.StaticFile("favicon.ico", "./resources/favicon.ico")
+func (s *Server) StaticFile(relativePath, filepath string) {
+	s.staticFile[relativePath] = filepath
+}
+
+// Use attaches a global middleware to the router. ie. the middleware attached though Use() will be
+// included in the handlers chain for every single request. Even 404, 405, static files...
+//
+// For example, this is the right place for a logger or error management middleware.
+func (s *Server) Use(handlers ...app.HandlerFunc) {
+	s.uses = append(s.uses, handlers...)
+}
+
+// SetErrorHandler set final error handler of response.
+// ProblemErrorHandler is used by default.
+func (s *Server) SetErrorHandler(handler ErrorHandler) {
+	s.handleError = handler
 }
 
 // NoMethod sets the handlers called when the HTTP method does not match.
 func NoMethod(handlers ...app.HandlerFunc) {
-	noMethodHandlers = append(noMethodHandlers, handlers...)
+	defaultServer.NoMethod(handlers...)
 }
 
 // NoRoute adds handlers for NoRoute. It returns a 404 code by default.
 func NoRoute(handlers ...app.HandlerFunc) {
-	noRouteHandlers = append(noRouteHandlers, handlers...)
+	defaultServer.NoRoute(handlers...)
 }
 
 // Static serves files from the given file system root.
@@ -80,13 +264,13 @@ func NoRoute(handlers ...app.HandlerFunc) {
 //
 //	router.Static("/static", "/var/www")
 func Static(relativePath, root string) {
-	static[relativePath] = root
+	defaultServer.Static(relativePath, root)
 }
 
 // StaticFile registers a single route in order to Serve a single file of the local filesystem.
 // router.StaticFile("favicon.ico", "./resources/favicon.ico")
 func StaticFile(relativePath, filepath string) {
-	staticFile[relativePath] = filepath
+	defaultServer.StaticFile(relativePath, filepath)
 }
 
 // Use attaches a global middleware to the router. ie. the middleware attached though Use() will be
@@ -94,10 +278,11 @@ func StaticFile(relativePath, filepath string) {
 //
 // For example, this is the right place for a logger or error management middleware.
 func Use(handlers ...app.HandlerFunc) {
-	uses = append(uses, handlers...)
+	defaultServer.Use(handlers...)
 }
 
-// SetErrorHandler set final error handler of response
+// SetErrorHandler set final error handler of response.
+// ProblemErrorHandler is used by default.
 func SetErrorHandler(handler ErrorHandler) {
-	handleError = handler
+	defaultServer.SetErrorHandler(handler)
 }
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+)
+
+func TestHertzOptions(t *testing.T) {
+	applied := 0
+	option := config.Option{F: func(*config.Options) { applied++ }}
+
+	for range 3 {
+		applied = 0
+
+		Hertz(option)
+
+		// the option is applied to the Server and to the hertz server.
+		if applied != 2 {
+			t.Errorf("option applied %d times, want 2", applied)
+		}
+	}
+
+	if len(defaultServer.opts) != 0 {
+		t.Errorf("the default Server has %d options, want the ones of Hertz left out", len(defaultServer.opts))
+	}
+}
@@ -0,0 +1,116 @@
+package servertest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/maadiii/hertz/server"
+)
+
+// Recorder holds the response of a request performed by a Client.
+type Recorder struct {
+	response *ut.ResponseRecorder
+	identity *server.Identity
+}
+
+// Status returns the response status code.
+func (r *Recorder) Status() int {
+	return r.response.Code
+}
+
+// Header returns the value of a response header.
+func (r *Recorder) Header(key string) string {
+	return r.response.Header().Get(key)
+}
+
+// Headers returns the response headers.
+func (r *Recorder) Headers() *protocol.ResponseHeader {
+	return r.response.Header()
+}
+
+// Body returns the response body.
+func (r *Recorder) Body() []byte {
+	return r.response.Body.Bytes()
+}
+
+// DecodeJSON decodes the JSON response body into v.
+func (r *Recorder) DecodeJSON(v any) error {
+	return json.Unmarshal(r.Body(), v)
+}
+
+// DecodeXML decodes the XML response body into v.
+func (r *Recorder) DecodeXML(v any) error {
+	return xml.Unmarshal(r.Body(), v)
+}
+
+// Identity returns the identity the identifier set on the request.
+func (r *Recorder) Identity() (server.Identity, bool) {
+	if r.identity == nil {
+		return server.Identity{}, false
+	}
+
+	return *r.identity, true
+}
+
+// AssertStatus fails the test if the response status is not status.
+func (r *Recorder) AssertStatus(t testing.TB, status int) {
+	t.Helper()
+
+	if r.Status() != status {
+		t.Errorf("status = %d, want %d, body: %s", r.Status(), status, r.Body())
+	}
+}
+
+// AssertHeader fails the test if the response header key does not have value.
+func (r *Recorder) AssertHeader(t testing.TB, key, value string) {
+	t.Helper()
+
+	if got := r.Header(key); got != value {
+		t.Errorf("header %s = %q, want %q", key, got, value)
+	}
+}
+
+// AssertIdentity fails the test if the identity of the request is not identity.
+func (r *Recorder) AssertIdentity(t testing.TB, identity server.Identity) {
+	t.Helper()
+
+	got, ok := r.Identity()
+	if !ok {
+		t.Errorf("identity is not set, want %+v", identity)
+
+		return
+	}
+
+	if !reflect.DeepEqual(got, identity) {
+		t.Errorf("identity = %+v, want %+v", got, identity)
+	}
+}
+
+// AssertNoIdentity fails the test if an identity is set on the request.
+func (r *Recorder) AssertNoIdentity(t testing.TB) {
+	t.Helper()
+
+	if got, ok := r.Identity(); ok {
+		t.Errorf("identity = %+v, want none", got)
+	}
+}
+
+// JSON decodes the JSON response body of r as T.
+func JSON[T any](r *Recorder) (T, error) {
+	var v T
+	err := r.DecodeJSON(&v)
+
+	return v, err
+}
+
+// XML decodes the XML response body of r as T.
+func XML[T any](r *Recorder) (T, error) {
+	var v T
+	err := r.DecodeXML(&v)
+
+	return v, err
+}
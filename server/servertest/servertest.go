@@ -0,0 +1,161 @@
+// Package servertest performs requests against the handlers registered on a
+// server.Server without listening on a network port.
+//
+//	client, err := servertest.New(server.Default())
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//
+//	rec := client.GET("/api/v1/json/5").WithHeader("authorize", "token").Do()
+//	rec.AssertStatus(t, http.StatusOK)
+package servertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/maadiii/hertz/server"
+)
+
+// Client performs requests against a built server.
+type Client struct {
+	engine *route.Engine
+}
+
+// New builds s and returns a client of its handlers. It must be called after
+// the handlers, decorators and identifier of s are registered, and returns the
+// *server.ValidationError of s if its routes are invalid.
+func New(s *server.Server) (*Client, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Client{engine: s.Build().Engine}, nil
+}
+
+func (c *Client) GET(path string) *Request {
+	return c.Request("GET", path)
+}
+
+func (c *Client) HEAD(path string) *Request {
+	return c.Request("HEAD", path)
+}
+
+func (c *Client) POST(path string) *Request {
+	return c.Request("POST", path)
+}
+
+func (c *Client) PUT(path string) *Request {
+	return c.Request("PUT", path)
+}
+
+func (c *Client) PATCH(path string) *Request {
+	return c.Request("PATCH", path)
+}
+
+func (c *Client) DELETE(path string) *Request {
+	return c.Request("DELETE", path)
+}
+
+// Request starts a request with the given method and path.
+func (c *Client) Request(method, path string) *Request {
+	return &Request{client: c, method: method, path: path, query: make(url.Values)}
+}
+
+// Request is a request being built by a Client.
+type Request struct {
+	client  *Client
+	method  string
+	path    string
+	query   url.Values
+	headers []ut.Header
+	body    []byte
+}
+
+// WithHeader adds a request header.
+func (r *Request) WithHeader(key, value string) *Request {
+	r.headers = append(r.headers, ut.Header{Key: key, Value: value})
+
+	return r
+}
+
+// WithQuery adds a query parameter.
+func (r *Request) WithQuery(key, value string) *Request {
+	r.query.Add(key, value)
+
+	return r
+}
+
+// WithBody sets the request body and its content type.
+func (r *Request) WithBody(contentType string, body []byte) *Request {
+	r.body = body
+
+	return r.WithHeader("Content-Type", contentType)
+}
+
+// WithJSON sets v encoded as JSON as the request body. It panics if v cannot be encoded.
+func (r *Request) WithJSON(v any) *Request {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+
+	return r.WithBody("application/json", body)
+}
+
+// WithForm sets the url encoded form values as the request body.
+func (r *Request) WithForm(values url.Values) *Request {
+	return r.WithBody("application/x-www-form-urlencoded", []byte(values.Encode()))
+}
+
+// Do performs the request.
+func (r *Request) Do() *Recorder {
+	target := r.path
+	if len(r.query) > 0 {
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+
+		target += separator + r.query.Encode()
+	}
+
+	rctx := r.client.engine.NewContext()
+
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+
+	req := protocol.NewRequest(r.method, target, body)
+	req.CopyTo(&rctx.Request)
+	rctx.Request.SetBody(r.body)
+
+	for _, header := range r.headers {
+		rctx.Request.Header.Add(header.Key, header.Value)
+	}
+
+	// the request context is not released once served, so its identity is still set.
+	r.client.engine.ServeHTTP(context.Background(), rctx)
+
+	response := ut.NewRecorder()
+	rctx.Response.Header.CopyTo(response.Header())
+	response.WriteHeader(rctx.Response.StatusCode())
+	_, _ = response.Write(rctx.Response.Body())
+	response.Flush()
+
+	rec := &Recorder{response: response}
+
+	if identity, ok := server.IdentityOf(rctx); ok {
+		rec.identity = &identity
+	}
+
+	return rec
+}
@@ -0,0 +1,138 @@
+package servertest
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/maadiii/hertz/server"
+)
+
+type itemRequest struct {
+	ID   int    `path:"id"`
+	Name string `query:"name" form:"name"`
+	Note string `json:"note"`
+}
+
+type item struct {
+	ID   int    `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+	Note string `json:"note" xml:"note"`
+}
+
+// createItem echoes the item of the request.
+//
+// [POST] /items/:id 200 json
+func createItem(_ context.Context, _ *server.Request, in *itemRequest) (*item, error) {
+	return &item{ID: in.ID, Name: in.Name, Note: in.Note}, nil
+}
+
+// getItem echoes the item of the request as XML.
+//
+// [GET] /items/:id 200 xml
+func getItem(_ context.Context, req *server.Request, in *itemRequest) (*item, error) {
+	req.SetHeader("X-Item", in.Name)
+
+	return &item{ID: in.ID, Name: in.Name}, nil
+}
+
+// me is only served to identified requests.
+//
+// @authorize
+// [GET] /me 200 json
+func me(_ context.Context, _ *server.Request, _ *struct{}) (map[string]bool, error) {
+	return map[string]bool{"ok": true}, nil
+}
+
+func newServer() *server.Server {
+	s := server.New()
+	s.SetIdentifier(func(c context.Context, req *server.Request, _ []string, _ ...string) {
+		token := string(req.GetHeader("Authorization"))
+		if token == "" {
+			req.Fail(c, server.Unauthorized("no token"))
+
+			return
+		}
+
+		req.SetIdentity(server.Identity{ID: token, Role: "user"})
+	})
+	s.Register(server.NewAction(createItem), server.NewAction(getItem), server.NewAction(me))
+
+	return s
+}
+
+func TestClient(t *testing.T) {
+	client, err := New(newServer())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := client.POST("/items/5").WithQuery("name", "pen").WithJSON(map[string]string{"note": "blue"}).Do()
+	rec.AssertStatus(t, http.StatusOK)
+	rec.AssertNoIdentity(t)
+
+	got, err := JSON[item](rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (item{ID: 5, Name: "pen", Note: "blue"}); got != want {
+		t.Errorf("JSON = %+v, want %+v", got, want)
+	}
+
+	rec = client.GET("/items/7?name=cup").Do()
+	rec.AssertStatus(t, http.StatusOK)
+	rec.AssertHeader(t, "X-Item", "cup")
+
+	if got, err := XML[item](rec); err != nil || got.ID != 7 || got.Name != "cup" {
+		t.Errorf("XML = %+v, %v, want the item 7 cup", got, err)
+	}
+
+	rec = client.POST("/items/1").WithForm(url.Values{"name": {"ink"}}).Do()
+	rec.AssertStatus(t, http.StatusOK)
+
+	if got, _ := JSON[item](rec); got.Name != "ink" {
+		t.Errorf("form name = %q, want ink", got.Name)
+	}
+}
+
+func TestClientIdentity(t *testing.T) {
+	s := newServer()
+
+	// the clients of a server do not add to its handlers.
+	for range 2 {
+		client, err := New(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec := client.GET("/me").WithHeader("Authorization", "alice").Do()
+		rec.AssertStatus(t, http.StatusOK)
+		rec.AssertIdentity(t, server.Identity{ID: "alice", Role: "user"})
+
+		rec = client.GET("/me").Do()
+		rec.AssertStatus(t, http.StatusUnauthorized)
+		rec.AssertNoIdentity(t)
+	}
+}
+
+// broken does not bind the id of its path.
+//
+// [GET] /broken/:id 200 json
+func broken(_ context.Context, _ *server.Request, _ *struct {
+	Name string `path:"name"`
+}) (map[string]bool, error) {
+	return nil, nil
+}
+
+func TestNewInvalid(t *testing.T) {
+	s := server.New()
+	s.Register(server.NewAction(broken))
+
+	client, err := New(s)
+	if err == nil || !strings.Contains(err.Error(), "path param id") {
+		t.Errorf("New() = %v, %v, want the unbound path param", client, err)
+	}
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
+)
+
+const defaultHeartbeat = 15 * time.Second
+
+// Event is a server-sent event. Handlers with the sse responder return a `<-chan Event`,
+// and each event is flushed to the client as soon as it is received.
+//
+//	// [GET] /jobs/:id/progress 200 sse(10s)
+//	func Progress(c context.Context, req *server.Request, in *ProgressRequest) (<-chan server.Event, error)
+//
+// The optional argument of sse is the interval of heartbeat comments, 15s by default.
+// The stream ends when the channel is closed, the client is disconnected or the
+// request context is cancelled. A disconnected client is noticed by the next write,
+// the heartbeat at the latest.
+type Event struct {
+	// ID and Event cannot contain line breaks: an event with one is not sent, and is
+	// logged as an error.
+	ID    string
+	Event string
+	// Data is written as is if it is a string or []byte, otherwise it is encoded as JSON.
+	Data  any
+	Retry time.Duration
+}
+
+// ResumeFn returns the events a client missed after lastEventID, the value of the
+// Last-Event-ID header of a reconnecting client.
+type ResumeFn func(c context.Context, req *Request, lastEventID string) ([]Event, error)
+
+// SetResumer sets the function sending the missed events to reconnecting sse clients.
+func (s *Server) SetResumer(resume ResumeFn) {
+	s.resume = resume
+}
+
+// SetResumer sets the function sending the missed events to reconnecting sse clients.
+func SetResumer(resume ResumeFn) {
+	defaultServer.SetResumer(resume)
+}
+
+// LastEventID returns the Last-Event-ID header of a reconnecting sse client.
+func (req *Request) LastEventID() string {
+	return string(req.rc.GetHeader("Last-Event-ID"))
+}
+
+// lineBreaks are the line endings of the event stream format.
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+func (e Event) encode() ([]byte, error) {
+	if strings.ContainsAny(e.ID, "\r\n") || strings.ContainsAny(e.Event, "\r\n") {
+		return nil, fmt.Errorf("the id %q or the event %q of an sse event has a line break", e.ID, e.Event)
+	}
+
+	var buf bytes.Buffer
+
+	if e.ID != "" {
+		fmt.Fprintf(&buf, "id: %s\n", e.ID)
+	}
+
+	if e.Event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", e.Event)
+	}
+
+	if e.Retry > 0 {
+		fmt.Fprintf(&buf, "retry: %d\n", e.Retry.Milliseconds())
+	}
+
+	var data string
+
+	switch d := e.Data.(type) {
+	case nil:
+	case string:
+		data = d
+	case []byte:
+		data = string(d)
+	default:
+		b, err := json.Marshal(d)
+		if err != nil {
+			return nil, err
+		}
+
+		data = string(b)
+	}
+
+	for _, line := range strings.Split(lineBreaks.Replace(data), "\n") {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+// sseHeartbeat returns the heartbeat interval of a `sse(10s)` responder type.
+func sseHeartbeat(responderType string) (time.Duration, bool, error) {
+	args, ok := strings.CutPrefix(responderType, "sse")
+	if !ok || (args != "" && !strings.HasPrefix(args, "(")) {
+		return 0, false, nil
+	}
+
+	args = strings.TrimSuffix(strings.TrimPrefix(args, "("), ")")
+	if args == "" {
+		return defaultHeartbeat, true, nil
+	}
+
+	interval, err := time.ParseDuration(args)
+	if err == nil && interval <= 0 {
+		err = fmt.Errorf("%s is not positive, and a disconnected client is only noticed by a heartbeat", args)
+	}
+
+	return interval, true, err
+}
+
+func (h *Handler[IN, OUT]) setSSEResponder(name string) bool {
+	heartbeat, ok, err := sseHeartbeat(h.ResponderType)
+	if !ok {
+		return false
+	}
+
+	if err != nil {
+		h.invalid("invalid sse heartbeat: %v", err)
+	}
+
+	h.RespondFn = func(c context.Context, ctx *app.RequestContext, res any) error {
+		var events <-chan Event
+
+		switch ch := res.(type) {
+		case <-chan Event:
+			events = ch
+		case chan Event:
+			events = ch
+		}
+
+		if events == nil {
+			return fmt.Errorf("%s must return <-chan server.Event", name)
+		}
+
+		stream := newEventStream(ctx, h.status(ctx))
+
+		if lastEventID := string(ctx.GetHeader("Last-Event-ID")); lastEventID != "" {
+			if s := serverOf(ctx); s != nil && s.resume != nil {
+				missed, err := s.resume(c, &Request{ctx}, lastEventID)
+				if err != nil {
+					return err
+				}
+
+				for _, event := range missed {
+					if err := stream.send(c, event); err != nil {
+						return nil
+					}
+				}
+			}
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.Done():
+				return nil
+			case <-ticker.C:
+				if stream.write([]byte(": heartbeat\n\n")) != nil {
+					return nil
+				}
+			case event, ok := <-events:
+				if !ok {
+					return nil
+				}
+
+				if stream.send(c, event) != nil {
+					return nil
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+type eventStream struct {
+	ctx    *app.RequestContext
+	writer network.ExtWriter
+}
+
+func newEventStream(ctx *app.RequestContext, status int) *eventStream {
+	ctx.SetStatusCode(status)
+	ctx.Response.Header.SetContentType("text/event-stream")
+	ctx.Response.Header.Set("Cache-Control", "no-cache")
+	ctx.Response.Header.Set("X-Accel-Buffering", "no")
+
+	stream := &eventStream{ctx: ctx}
+
+	if w := ctx.GetWriter(); w != nil {
+		stream.writer = resp.NewChunkedBodyWriter(&ctx.Response, w)
+		ctx.Response.HijackWriter(stream.writer)
+	}
+
+	return stream
+}
+
+// send writes an event. An event which cannot be encoded is logged and skipped.
+func (s *eventStream) send(c context.Context, event Event) error {
+	b, err := event.encode()
+	if err != nil {
+		hlog.CtxErrorf(c, "sse: %v", err)
+
+		return nil
+	}
+
+	return s.write(b)
+}
+
+// write writes p and flushes it to the client. Without a connection, as in
+// servertest, it is appended to the response body.
+func (s *eventStream) write(p []byte) error {
+	if s.writer == nil {
+		_, err := s.ctx.Write(p)
+
+		return err
+	}
+
+	if _, err := s.writer.Write(p); err != nil {
+		return err
+	}
+
+	return s.writer.Flush()
+}
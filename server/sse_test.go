@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+func TestEventEncode(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{"fields", Event{ID: "7", Event: "progress", Data: "50%", Retry: 2 * time.Second}, "id: 7\nevent: progress\nretry: 2000\ndata: 50%\n\n"},
+		{"lines", Event{Data: "a\r\nb\rc\nd"}, "data: a\ndata: b\ndata: c\ndata: d\n\n"},
+		{"bytes", Event{Data: []byte("raw")}, "data: raw\n\n"},
+		{"json", Event{Data: map[string]int{"done": 1}}, "data: {\"done\":1}\n\n"},
+		{"empty", Event{}, "data: \n\n"},
+	}
+
+	for _, tt := range tests {
+		b, err := tt.event.encode()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+
+			continue
+		}
+
+		if string(b) != tt.want {
+			t.Errorf("%s: encoded %q, want %q", tt.name, b, tt.want)
+		}
+	}
+
+	for _, event := range []Event{{ID: "1\n2"}, {Event: "a\rb"}} {
+		if _, err := event.encode(); err == nil {
+			t.Errorf("%+v: encoded, want the line break rejected", event)
+		}
+	}
+}
+
+func TestSSEHeartbeat(t *testing.T) {
+	tests := []struct {
+		responderType string
+		interval      time.Duration
+		sse           bool
+		invalid       bool
+	}{
+		{"sse", defaultHeartbeat, true, false},
+		{"sse(10s)", 10 * time.Second, true, false},
+		{"sse(0s)", 0, true, true},
+		{"sse(soon)", 0, true, true},
+		{"ssex", 0, false, false},
+		{"json", 0, false, false},
+	}
+
+	for _, tt := range tests {
+		interval, sse, err := sseHeartbeat(tt.responderType)
+		if sse != tt.sse || (err != nil) != tt.invalid || (!tt.invalid && interval != tt.interval) {
+			t.Errorf("sseHeartbeat(%q) = %v, %v, %v", tt.responderType, interval, sse, err)
+		}
+	}
+}
+
+// jobProgress sends the progress of a job, skipping an event with a line break.
+//
+// [GET] /jobs/progress 200 sse
+func jobProgress(_ context.Context, _ *Request, _ *struct{}) (<-chan Event, error) {
+	events := make(chan Event, 3)
+	events <- Event{ID: "2", Data: "50%"}
+	events <- Event{ID: "bad\nid", Data: "skipped"}
+	events <- Event{ID: "3", Data: "100%"}
+	close(events)
+
+	return events, nil
+}
+
+// slowProgress sends an event after a few heartbeats.
+//
+// [GET] /jobs/slow 200 sse(5ms)
+func slowProgress(_ context.Context, _ *Request, _ *struct{}) (<-chan Event, error) {
+	events := make(chan Event)
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		events <- Event{Data: "done"}
+		close(events)
+	}()
+
+	return events, nil
+}
+
+func TestSSE(t *testing.T) {
+	s := New()
+	s.SetResumer(func(_ context.Context, _ *Request, lastEventID string) ([]Event, error) {
+		return []Event{{ID: "1", Data: "missed after " + lastEventID}}, nil
+	})
+	s.Register(NewAction(jobProgress), NewAction(slowProgress))
+
+	engine := s.Build().Engine
+
+	res := ut.PerformRequest(engine, http.MethodGet, "/jobs/progress", nil)
+	if res.Code != http.StatusOK || res.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("stream = %d %q", res.Code, res.Header().Get("Content-Type"))
+	}
+
+	if body, want := res.Body.String(), "id: 2\ndata: 50%\n\nid: 3\ndata: 100%\n\n"; !strings.Contains(body, want) || strings.Contains(body, "skipped") {
+		t.Errorf("body = %q, want %q without the invalid event", body, want)
+	}
+
+	res = ut.PerformRequest(engine, http.MethodGet, "/jobs/progress", nil, ut.Header{Key: "Last-Event-ID", Value: "0"})
+	if body := res.Body.String(); !strings.HasPrefix(body, "id: 1\ndata: missed after 0\n\nid: 2\n") {
+		t.Errorf("resumed body = %q, want the missed events first", body)
+	}
+
+	res = ut.PerformRequest(engine, http.MethodGet, "/jobs/slow", nil)
+	if body := res.Body.String(); !strings.Contains(body, ": heartbeat\n\n") || !strings.HasSuffix(body, "data: done\n\n") {
+		t.Errorf("slow body = %q, want heartbeats before the event", body)
+	}
+}
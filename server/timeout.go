@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// SetDefaultTimeout sets the timeout of the handlers without a `@timeout(2s)` directive.
+// The handlers of sse and websocket routes, and of IN structs with File or Parts fields,
+// have no default timeout.
+//
+// The context of a handler has the deadline of its timeout. Once it expires, the
+// request is answered with 503 through the ErrorHandler, and the result of the handler
+// is discarded, so its responder never writes. A request cancelled before its handler
+// returns is aborted with 499 instead, without the ErrorHandler. The handler runs on a
+// copy of the request context, and its headers, cookies and keys are applied if it
+// returns in time.
+// An abandoned handler keeps running on the copy until it returns, while the request
+// context is reused, so the handlers reading the request body, as the File and Parts
+// fields do, cannot have a `@timeout` directive.
+func (s *Server) SetDefaultTimeout(timeout time.Duration) {
+	s.defaultTimeout = timeout
+}
+
+// SetDefaultTimeout sets the timeout of the handlers without a `@timeout(2s)` directive.
+func SetDefaultTimeout(timeout time.Duration) {
+	defaultServer.SetDefaultTimeout(timeout)
+}
+
+// getTimeout returns the timeout of the `@timeout(2s)` directive, zero if it has none.
+func (h *Handler[IN, OUT]) getTimeout() time.Duration {
+	comment := funcDescription(h.HandlerFn)
+	comments := strings.Split(comment, "\n")
+
+	for _, describer := range comments {
+		if !strings.HasPrefix(describer, "@timeout") {
+			continue
+		}
+
+		timeout, err := parseTimeout(describer)
+		if err != nil {
+			h.invalid("%v", err)
+		}
+
+		if len(h.files) > 0 {
+			h.invalid("%s cannot be used with the file fields of %s, which an abandoned handler would read after the request is released", describer, reflect.TypeOf(h.HandlerFn).In(2))
+		}
+
+		return timeout
+	}
+
+	return 0
+}
+
+func parseTimeout(directive string) (time.Duration, error) {
+	_, args, err := parseDecorator(strings.TrimPrefix(directive, "@"))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(args) != 1 {
+		return 0, fmt.Errorf("invalid %s, expected @timeout(duration)", directive)
+	}
+
+	timeout, err := time.ParseDuration(args[0])
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid duration of %s", directive)
+	}
+
+	return timeout, nil
+}
+
+// timeout returns the timeout of the handler, or the default of the server.
+func (h *Handler[IN, OUT]) timeout(s *Server) time.Duration {
+	if h.Timeout > 0 {
+		return h.Timeout
+	}
+
+	if _, ok, _ := sseHeartbeat(h.ResponderType); ok || h.ResponderType == "websocket" {
+		return 0
+	}
+
+	// an abandoned handler would read the parts or files of a request already released.
+	if len(h.files) > 0 {
+		return 0
+	}
+
+	return s.defaultTimeout
+}
+
+// statusClientClosedRequest is the status of the requests cancelled before their
+// handler returned, kept for the access logs since the client is gone.
+const statusClientClosedRequest = 499
+
+// abandonedKey holds the channel closed once an abandoned handler returns.
+const abandonedKey = "abandoned"
+
+// callWithTimeout calls the handler on a copy of the request context, and returns
+// a 503 error once the deadline of c expires if the handler does not return before,
+// or the error of c once it is cancelled.
+// The handler is then abandoned, and the request context has a channel closed once
+// it returns, as the value of abandonedKey.
+func callWithTimeout[IN any, OUT any](c context.Context, rctx *app.RequestContext, handler *Handler[IN, OUT], in IN) (OUT, error) {
+	var (
+		res  OUT
+		err  error
+		done = make(chan struct{})
+		cp   = rctx.Copy()
+	)
+
+	go func() {
+		defer close(done)
+
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v\n%v", r, string(debug.Stack()))
+			}
+		}()
+
+		res, err = handler.HandlerFn(c, &Request{cp}, in)
+	}()
+
+	select {
+	case <-done:
+	case <-c.Done():
+		var zero OUT
+
+		// the decorators keep what the abandoned handler still uses until it returns.
+		rctx.Set(abandonedKey, (<-chan struct{})(done))
+
+		if errors.Is(c.Err(), context.DeadlineExceeded) {
+			return zero, ServiceUnavailable("the handler timed out").WithCode("timeout").Wrap(c.Err())
+		}
+
+		return zero, c.Err()
+	}
+
+	cp.Response.Header.CopyTo(&rctx.Response.Header)
+	cp.ForEachKey(func(key string, value any) {
+		rctx.Set(key, value)
+	})
+
+	return res, err
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+// sleepPast sleeps past its timeout.
+//
+// @timeout(10ms)
+// [GET] /sleep 200 json
+func sleepPast(c context.Context, _ *Request, _ *struct{}) (map[string]bool, error) {
+	<-c.Done()
+	time.Sleep(10 * time.Millisecond)
+
+	return map[string]bool{"ok": true}, nil
+}
+
+// returnInTime sets a header and a key before its timeout.
+//
+// @copied
+// @timeout(1s)
+// [GET] /in-time 200 json
+func returnInTime(_ context.Context, req *Request, _ *struct{}) (map[string]bool, error) {
+	req.SetHeader("X-Handler", "set")
+	req.Set("handler", "set")
+
+	return map[string]bool{"ok": true}, nil
+}
+
+// cancelled returns the error of its cancelled context.
+//
+// @timeout(1s)
+// [GET] /cancelled 200 json
+func cancelled(c context.Context, _ *Request, _ *struct{}) (map[string]bool, error) {
+	<-c.Done()
+
+	return nil, c.Err()
+}
+
+func TestTimeout(t *testing.T) {
+	s := New()
+	s.AddDecorator("copied", func(c context.Context, req *Request) {
+		req.Next(c)
+
+		if value, _ := req.Value("handler").(string); value != "" {
+			req.SetHeader("X-Key", value)
+		}
+	})
+	s.Register(NewAction(sleepPast), NewAction(returnInTime))
+
+	engine := s.Build().Engine
+
+	res := ut.PerformRequest(engine, http.MethodGet, "/sleep", nil)
+	if res.Code != http.StatusServiceUnavailable || !strings.Contains(res.Body.String(), `"code":"timeout"`) {
+		t.Errorf("timed out = %d %s, want the 503 timeout problem", res.Code, res.Body.String())
+	}
+
+	res = ut.PerformRequest(engine, http.MethodGet, "/in-time", nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("in time = %d %s", res.Code, res.Body.String())
+	}
+
+	if header, key := res.Header().Get("X-Handler"), res.Header().Get("X-Key"); header != "set" || key != "set" {
+		t.Errorf("header %q and key %q of the handler, want both copied back", header, key)
+	}
+}
+
+func TestTimeoutCancelled(t *testing.T) {
+	s := New()
+
+	var handled error
+
+	s.SetErrorHandler(func(_ context.Context, _ *app.RequestContext, err error) {
+		handled = err
+	})
+
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rctx := app.NewContext(0)
+	rctx.Request.SetMethod(http.MethodGet)
+	rctx.Request.SetRequestURI("/cancelled")
+
+	NewAction(cancelled).route.handle(s)(c, rctx)
+
+	if status := rctx.Response.StatusCode(); status != statusClientClosedRequest {
+		t.Errorf("status = %d, want %d", status, statusClientClosedRequest)
+	}
+
+	if handled != nil {
+		t.Errorf("ErrorHandler called with %v, want the cancelled request not answered", handled)
+	}
+}
+
+// streamTicks has no default timeout.
+//
+// [GET] /ticks 200 sse
+func streamTicks(_ context.Context, _ *Request, _ *struct{}) (<-chan Event, error) {
+	return nil, nil
+}
+
+// openChat has no default timeout.
+//
+// [GET] /chat 101 websocket
+func openChat(_ context.Context, _ *Request, _ *struct{}) (WebSocket, error) {
+	return nil, nil
+}
+
+// uploadAvatar has no default timeout.
+//
+// [POST] /avatar 204 json
+func uploadAvatar(_ context.Context, _ *Request, _ *struct {
+	Avatar *File `form:"avatar"`
+}) (map[string]bool, error) {
+	return nil, nil
+}
+
+// timeoutOf returns the timeout of the action in s.
+func timeoutOf[IN any, OUT any](s *Server, action func(context.Context, *Request, IN) (OUT, error)) time.Duration {
+	handler := &Handler[IN, OUT]{HandlerFn: action}
+	handler.fixAPIDescriber()
+	handler.files = handler.getFiles()
+	handler.Timeout = handler.getTimeout()
+
+	return handler.timeout(s)
+}
+
+func TestTimeoutExemptions(t *testing.T) {
+	s := New()
+	s.SetDefaultTimeout(time.Second)
+
+	if timeout := timeoutOf(s, returnInTime); timeout != time.Second {
+		t.Errorf("timeout of @timeout(1s) = %v", timeout)
+	}
+
+	if timeout := timeoutOf(s, createOrder); timeout != time.Second {
+		t.Errorf("default timeout = %v, want 1s", timeout)
+	}
+
+	if timeout := timeoutOf(s, streamTicks); timeout != 0 {
+		t.Errorf("timeout of the sse route = %v, want none", timeout)
+	}
+
+	if timeout := timeoutOf(s, openChat); timeout != 0 {
+		t.Errorf("timeout of the websocket route = %v, want none", timeout)
+	}
+
+	if timeout := timeoutOf(s, uploadAvatar); timeout != 0 {
+		t.Errorf("timeout of the file route = %v, want none", timeout)
+	}
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ValidationError reports every problem of the registered routes found by Validate.
+type ValidationError struct {
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+
+	b.WriteString("server: invalid routes:")
+
+	for _, problem := range e.Problems {
+		b.WriteString("\n\t")
+		b.WriteString(problem.Error())
+	}
+
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Problems
+}
+
+// Validate checks the describers and directives of the registered routes against
+// the server, and returns a *ValidationError reporting all their problems, such as
+// duplicate routes or groups, routes served by the server itself as /healthz, unknown
+// verbs, responders, encoders or decorators, invalid statuses, @authorize without an
+// identifier or path params missing from the IN struct.
+// Build panics with the error, so a server with invalid routes fails to start.
+func (s *Server) Validate() error {
+	var problems []error
+
+	routes := make(map[string]*routeDescriber)
+	builtins := s.builtinRoutes()
+
+	for _, r := range s.routes {
+		errs := s.validateRoute(r)
+
+		if r.described() && r.hasGroup() {
+			key := r.Verb + " " + pathParam.ReplaceAllStringFunc(r.FullPath(), func(param string) string {
+				return param[:1]
+			})
+
+			if builtin, ok := builtins[key]; ok {
+				errs = append(errs, fmt.Errorf("the route is also served by %s", builtin))
+			} else if other, ok := routes[key]; ok {
+				errs = append(errs, fmt.Errorf("the route is also registered by %s", other.FunctionName))
+			} else {
+				routes[key] = r
+			}
+		}
+
+		for _, err := range errs {
+			problems = append(problems, fmt.Errorf("%s: %w", r.label(), err))
+		}
+	}
+
+	for _, path := range s.duplicateGroups {
+		problems = append(problems, fmt.Errorf("group %s: it is created more than once, and the @group directives cannot tell the groups apart", path))
+	}
+
+	if s.routesAuthorize != nil && s.identifier == nil {
+		problems = append(problems, fmt.Errorf("%s: @authorize requires an identifier, set by SetIdentifier", routesPath))
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// builtinRoutes returns the descriptions of the routes the server adds, by verb and path.
+func (s *Server) builtinRoutes() map[string]string {
+	routes := make(map[string]string)
+
+	if s.openAPIInfo != nil {
+		routes[http.MethodGet+" "+openAPIPath] = "the OpenAPI document"
+
+		if s.swaggerUIPath != "" {
+			routes[http.MethodGet+" "+s.swaggerUIPath] = "the Swagger UI"
+		}
+	}
+
+	if s.health.serve {
+		for _, path := range []string{healthPath, readyPath, livePath} {
+			routes[http.MethodGet+" "+path] = "the health probes"
+		}
+	}
+
+	if s.routesAuthorize != nil {
+		routes[http.MethodGet+" "+routesPath] = "the routes listing"
+	}
+
+	return routes
+}
+
+// Validate checks the routes registered in the default Server.
+func Validate() error {
+	return defaultServer.Validate()
+}
+
+var verbs = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// validateRoute returns the problems of a route. The problems of its describer stop
+// the other checks, which depend on it.
+func (s *Server) validateRoute(r *routeDescriber) []error {
+	if !r.described() {
+		return r.errs
+	}
+
+	// the params of a route registered before its group was created are checked once it is.
+	if !r.pathChecked && r.hasGroup() {
+		r.checkPathParams()
+	}
+
+	errs := append([]error(nil), r.errs...)
+
+	if !verbs[r.Verb] {
+		errs = append(errs, fmt.Errorf("unknown verb %q", r.Verb))
+	}
+
+	switch {
+	case r.Status == 0:
+		errs = append(errs, fmt.Errorf("the describer has no status"))
+	case r.Status < 100 || r.Status > 599:
+		errs = append(errs, fmt.Errorf("invalid status %d", r.Status))
+	case r.ResponderType == "redirect" && (r.Status < 300 || r.Status > 399):
+		errs = append(errs, fmt.Errorf("invalid status %d of a redirect, expected 3xx", r.Status))
+	}
+
+	switch r.ResponderType {
+	case "stream", "data", "attachment":
+		if r.ContentType == "" {
+			errs = append(errs, fmt.Errorf("the %s responder has no content type, expected %s@type/subtype", r.ResponderType, r.ResponderType))
+		}
+	}
+
+	if names, ok := negotiateEncoders(r.ResponderType); ok {
+		for _, name := range names {
+			if _, ok := s.encoders.get(name); !ok {
+				errs = append(errs, fmt.Errorf("unknown encoder %q of the negotiate responder, expected one registered by AddEncoder", name))
+			}
+		}
+	}
+
+	if !r.hasGroup() {
+		return append(errs, fmt.Errorf("group %s does not exist", r.GroupPath))
+	}
+
+	if r.authorization() != nil && s.identifier == nil {
+		errs = append(errs, fmt.Errorf("@authorize requires an identifier, set by SetIdentifier"))
+	}
+
+	for _, directive := range slices.Concat(r.routerGroup().allDecorators(), r.Decorators) {
+		if _, err := r.decorator(directive); err != nil {
+			errs = append(errs, fmt.Errorf("@%s: %w", directive, err))
+		}
+
+		switch name, _, _ := parseDecorator(directive); {
+		// a cached response is replayed before the policies authorize the input.
+		case name == "cache" && len(r.Policies) > 0:
+			errs = append(errs, fmt.Errorf("@%s cannot be used with @policy", directive))
+		// a streamed or hijacked response cannot be stored.
+		case name == "cache" && r.streams():
+			errs = append(errs, fmt.Errorf("@%s cannot be used with the %s responder", directive, r.ResponderType))
+		}
+	}
+
+	for _, policy := range r.Policies {
+		if _, ok := s.policies[policy]; !ok {
+			errs = append(errs, fmt.Errorf("policy %s does not exist", policy))
+		}
+	}
+
+	if s.strictPathParams {
+		errs = append(errs, r.unusedPathTags...)
+	}
+
+	return errs
+}
+
+// streams reports whether the responder of the route streams its response.
+func (r *routeDescriber) streams() bool {
+	_, sse, _ := sseHeartbeat(r.ResponderType)
+
+	return sse || r.ResponderType == "websocket"
+}
+
+// described reports whether the route has a describer.
+func (r *routeDescriber) described() bool {
+	return r.apiDescriber != nil && (r.Verb != "" || r.Path != "")
+}
+
+// hasGroup reports whether the group of the route exists.
+func (r *routeDescriber) hasGroup() bool {
+	if r.group != nil || r.GroupPath == "" {
+		return true
+	}
+
+	_, ok := r.server.groups[r.GroupPath]
+
+	return ok
+}
+
+// label names a route in the problems of Validate.
+func (r *routeDescriber) label() string {
+	if !r.described() {
+		return r.FunctionName
+	}
+
+	path := r.Path
+	if r.hasGroup() {
+		path = r.FullPath()
+	}
+
+	return fmt.Sprintf("%s [%s] %s", r.FunctionName, r.Verb, path)
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/maadiii/hertz/server/openapi"
+)
+
+// healthz is served by the health probes too.
+//
+// [GET] /healthz 200 json
+func healthz(_ context.Context, _ *Request, _ *struct{}) (map[string]bool, error) {
+	return nil, nil
+}
+
+// docs is served by the Swagger UI too.
+//
+// [GET] /docs 200 json
+func docs(_ context.Context, _ *Request, _ *struct{}) (map[string]bool, error) {
+	return nil, nil
+}
+
+// listRoutes is served by the routes listing too.
+//
+// [GET] /_routes 200 json
+func listRoutes(_ context.Context, _ *Request, _ *struct{}) (map[string]bool, error) {
+	return nil, nil
+}
+
+// postHealthz does not collide with the GET of the probe.
+//
+// [POST] /healthz 204 json
+func postHealthz(_ context.Context, _ *Request, _ *struct{}) (map[string]bool, error) {
+	return nil, nil
+}
+
+// getThing collides with getOtherThing, whose param has another name.
+//
+// [GET] /things/:id 200 json
+func getThing(_ context.Context, _ *Request, _ *struct {
+	ID string `path:"id"`
+}) (map[string]bool, error) {
+	return nil, nil
+}
+
+// getOtherThing collides with getThing.
+//
+// [GET] /things/:name 200 json
+func getOtherThing(_ context.Context, _ *Request, _ *struct {
+	Name string `path:"name"`
+}) (map[string]bool, error) {
+	return nil, nil
+}
+
+// badStatus has an invalid status and an unknown responder.
+//
+// [GET] /bad 700 yaml
+func badStatus(_ context.Context, _ *Request, _ *struct{}) (map[string]bool, error) {
+	return nil, nil
+}
+
+// undescribed has no describer.
+func undescribed(_ context.Context, _ *Request, _ *struct{}) (map[string]bool, error) {
+	return nil, nil
+}
+
+func TestValidate(t *testing.T) {
+	s := New(WithHealth(0))
+	s.ServeOpenAPI(openapi.Info{Title: "test"}, "/docs")
+	s.ServeRoutes("")
+	s.SetIdentifier(identifyAs("1"))
+	s.Register(
+		NewAction(healthz), NewAction(docs), NewAction(listRoutes), NewAction(postHealthz),
+		NewAction(getThing), NewAction(getOtherThing), NewAction(badStatus), NewAction(undescribed),
+	)
+
+	err := s.Validate()
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Validate() = %v, want a *ValidationError", err)
+	}
+
+	want := []string{
+		"healthz [GET] /healthz: the route is also served by the health probes",
+		"docs [GET] /docs: the route is also served by the Swagger UI",
+		"listRoutes [GET] /_routes: the route is also served by the routes listing",
+		"getOtherThing [GET] /things/:name: the route is also registered by",
+		"badStatus [GET] /bad: invalid status 700",
+		"badStatus [GET] /bad: unknown responder",
+		"undescribed",
+	}
+
+	if len(validationErr.Problems) != len(want) {
+		t.Errorf("problems = %d, want %d:\n%v", len(validationErr.Problems), len(want), err)
+	}
+
+	for _, problem := range want {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("Validate() = %v, want %q", err, problem)
+		}
+	}
+
+	if lines := strings.Split(err.Error(), "\n\t"); lines[0] != "server: invalid routes:" || len(lines) != len(validationErr.Problems)+1 {
+		t.Errorf("Error() = %q, want a line per problem", err.Error())
+	}
+
+	if strings.Contains(err.Error(), "postHealthz") {
+		t.Errorf("Validate() = %v, want the POST of /healthz accepted", err)
+	}
+
+	// the problems are unwrapped by errors.Is and errors.As.
+	sentinel := errors.New("sentinel")
+	err = &ValidationError{Problems: []error{errors.New("other"), sentinel}}
+
+	if !errors.Is(err, sentinel) {
+		t.Errorf("errors.Is(%v, sentinel) = false", err)
+	}
+}
@@ -0,0 +1,332 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/hertz-contrib/websocket"
+)
+
+// The message types of a websocket connection.
+const (
+	TextMessage   = websocket.TextMessage
+	BinaryMessage = websocket.BinaryMessage
+)
+
+// WebSocket is the session of a websocket handler. Handlers with the websocket responder
+// return it after the request is bound, validated and identified, and it runs on the
+// upgraded connection.
+//
+//	// [GET] /ws/chat 101 websocket
+//	func Chat(c context.Context, req *server.Request, in *ChatRequest) (server.WebSocket, error) {
+//		return func(c context.Context, conn *server.Conn) error {
+//			var msg Message
+//			for conn.ReadJSON(&msg) == nil {
+//				_ = conn.WriteJSON(msg)
+//			}
+//
+//			return nil
+//		}, nil
+//	}
+//
+// The context of the session is cancelled when the server shuts down, and the
+// connection is closed once the session returns.
+type WebSocket func(c context.Context, conn *Conn) error
+
+// WebSocketConfig configures the websocket connections of a Server.
+type WebSocketConfig struct {
+	// PingInterval is the interval of the pings keeping the connection alive,
+	// 30s by default and negative to disable them.
+	PingInterval time.Duration
+	// PongWait is the time a read waits for the pong of a ping, 2*PingInterval by default.
+	PongWait time.Duration
+	// WriteWait is the time allowed to write a message, 10s by default.
+	WriteWait time.Duration
+	// MaxMessageSize is the maximum size in bytes of a read message, 1MB by default.
+	MaxMessageSize int64
+
+	ReadBufferSize    int
+	WriteBufferSize   int
+	Subprotocols      []string
+	EnableCompression bool
+	// CheckOrigin returns whether the Origin header is accepted. By default the
+	// origin must be the host of the request.
+	CheckOrigin func(rctx *app.RequestContext) bool
+}
+
+const (
+	defaultPingInterval   = 30 * time.Second
+	defaultWriteWait      = 10 * time.Second
+	defaultMaxMessageSize = 1 << 20
+)
+
+func (cfg WebSocketConfig) withDefaults() WebSocketConfig {
+	if cfg.PingInterval == 0 {
+		cfg.PingInterval = defaultPingInterval
+	}
+
+	if cfg.PongWait <= 0 {
+		cfg.PongWait = 2 * cfg.PingInterval
+	}
+
+	if cfg.WriteWait <= 0 {
+		cfg.WriteWait = defaultWriteWait
+	}
+
+	if cfg.MaxMessageSize <= 0 {
+		cfg.MaxMessageSize = defaultMaxMessageSize
+	}
+
+	return cfg
+}
+
+// SetWebSocketConfig sets the configuration of websocket connections.
+func (s *Server) SetWebSocketConfig(cfg WebSocketConfig) {
+	s.webSocket = cfg
+}
+
+// SetWebSocketConfig sets the configuration of websocket connections.
+func SetWebSocketConfig(cfg WebSocketConfig) {
+	defaultServer.SetWebSocketConfig(cfg)
+}
+
+// Conn is an upgraded websocket connection.
+type Conn struct {
+	conn      *websocket.Conn
+	raw       network.Conn
+	writeWait time.Duration
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+}
+
+// ReadJSON reads the next message and decodes it as JSON into v.
+func (c *Conn) ReadJSON(v any) error {
+	return c.conn.ReadJSON(v)
+}
+
+// WriteJSON writes v encoded as JSON as a text message.
+func (c *Conn) WriteJSON(v any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeWait))
+
+	return c.conn.WriteJSON(v)
+}
+
+// ReadMessage reads the next message and returns its type and payload.
+func (c *Conn) ReadMessage() (messageType int, p []byte, err error) {
+	return c.conn.ReadMessage()
+}
+
+// WriteMessage writes a message of the given type.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeWait))
+
+	return c.conn.WriteMessage(messageType, data)
+}
+
+// SetReadLimit sets the maximum size in bytes of the messages read from the connection.
+// The connection is closed when a message exceeds it.
+func (c *Conn) SetReadLimit(limit int64) {
+	c.conn.SetReadLimit(limit)
+}
+
+// Subprotocol returns the negotiated subprotocol of the connection.
+func (c *Conn) Subprotocol() string {
+	return c.conn.Subprotocol()
+}
+
+// Close sends a close message with the given code and reason and ends the session.
+func (c *Conn) Close(code int, reason string) error {
+	defer c.cancel()
+
+	return c.closeMessage(code, reason)
+}
+
+func (c *Conn) closeMessage(code int, reason string) error {
+	err := c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(c.writeWait))
+	if errors.Is(err, websocket.ErrCloseSent) {
+		return nil
+	}
+
+	return err
+}
+
+// keepAlive pings the peer until c is done and closes the connection if a pong is not read
+// in time. Pongs are read along with the messages, so a session must keep reading to stay alive.
+//
+// Read deadlines are not supported by every transport, so the pongs are checked on each ping.
+func (c *Conn) keepAlive(ctx context.Context, cfg WebSocketConfig) {
+	if cfg.PingInterval < 0 {
+		return
+	}
+
+	var lastPong atomic.Int64
+
+	lastPong.Store(time.Now().UnixNano())
+	c.conn.SetPongHandler(func(string) error {
+		lastPong.Store(time.Now().UnixNano())
+
+		return nil
+	})
+
+	go func() {
+		ticker := time.NewTicker(cfg.PingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if time.Since(time.Unix(0, lastPong.Load())) > cfg.PongWait {
+					_ = c.raw.Close()
+
+					return
+				}
+
+				if c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.writeWait)) != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// webSockets tracks the open connections of a Server to close them on shutdown.
+type webSockets struct {
+	mu       sync.Mutex
+	conns    map[*Conn]struct{}
+	sessions sync.WaitGroup
+	closed   bool
+}
+
+func (w *webSockets) open(conn *Conn) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return false
+	}
+
+	if w.conns == nil {
+		w.conns = make(map[*Conn]struct{})
+	}
+
+	w.conns[conn] = struct{}{}
+	w.sessions.Add(1)
+
+	return true
+}
+
+func (w *webSockets) release(conn *Conn) {
+	w.mu.Lock()
+	delete(w.conns, conn)
+	w.mu.Unlock()
+
+	w.sessions.Done()
+}
+
+// shutdown closes the open connections and waits for their sessions until c is done.
+func (w *webSockets) shutdown(c context.Context) {
+	w.mu.Lock()
+	w.closed = true
+
+	for conn := range w.conns {
+		_ = conn.Close(websocket.CloseGoingAway, "server is shutting down")
+		_ = conn.raw.Close()
+	}
+	w.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		w.sessions.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-c.Done():
+	}
+}
+
+func (h *Handler[IN, OUT]) setWebSocketResponder(name string) {
+	h.RespondFn = func(c context.Context, ctx *app.RequestContext, res any) error {
+		session, ok := res.(WebSocket)
+		if !ok || session == nil {
+			return fmt.Errorf("%s must return a server.WebSocket", name)
+		}
+
+		s := serverOrDefault(ctx)
+		cfg := s.webSocket.withDefaults()
+
+		var handshakeErr error
+
+		upgrader := &websocket.HertzUpgrader{
+			ReadBufferSize:    cfg.ReadBufferSize,
+			WriteBufferSize:   cfg.WriteBufferSize,
+			Subprotocols:      cfg.Subprotocols,
+			EnableCompression: cfg.EnableCompression,
+			CheckOrigin:       cfg.CheckOrigin,
+			Error: func(_ *app.RequestContext, status int, reason error) {
+				handshakeErr = NewHTTPError(status, reason.Error())
+			},
+		}
+
+		// the hijacked connection given to the session cannot be closed before it
+		// returns, so the connection of the request is closed on shutdown.
+		raw := ctx.GetConn()
+
+		// the request context is cancelled once the handler chain returns,
+		// so the session only keeps its values.
+		c = context.WithoutCancel(c)
+
+		err := upgrader.Upgrade(ctx, func(ws *websocket.Conn) {
+			c, cancel := context.WithCancel(c)
+			defer cancel()
+
+			conn := &Conn{conn: ws, raw: raw, writeWait: cfg.WriteWait, cancel: cancel}
+			if !s.webSockets.open(conn) {
+				_ = conn.Close(websocket.CloseGoingAway, "server is shutting down")
+
+				return
+			}
+			defer s.webSockets.release(conn)
+
+			conn.SetReadLimit(cfg.MaxMessageSize)
+			conn.keepAlive(c, cfg)
+
+			if err := session(c, conn); err != nil {
+				hlog.CtxErrorf(c, "%s websocket session: %v", name, err)
+				_ = conn.closeMessage(websocket.CloseInternalServerErr, http.StatusText(http.StatusInternalServerError))
+
+				return
+			}
+
+			_ = conn.closeMessage(websocket.CloseNormalClosure, "")
+		})
+		if err != nil {
+			if handshakeErr != nil {
+				return handshakeErr
+			}
+
+			return BadRequest(err.Error())
+		}
+
+		return nil
+	}
+}
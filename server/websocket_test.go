@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// echo echoes the text messages of its connection.
+//
+// [GET] /echo 101 websocket
+func echo(_ context.Context, _ *Request, _ *struct{}) (WebSocket, error) {
+	return func(_ context.Context, conn *Conn) error {
+		for {
+			var message string
+			if err := conn.ReadJSON(&message); err != nil {
+				return nil
+			}
+
+			if err := conn.WriteJSON(message); err != nil {
+				return err
+			}
+		}
+	}, nil
+}
+
+func TestWebSocketOutOfServer(t *testing.T) {
+	// the handlers of a route mounted on another engine use the default configuration.
+	rctx := app.NewContext(0)
+	rctx.Request.SetMethod(http.MethodGet)
+	rctx.Request.SetRequestURI("/echo")
+
+	NewAction(echo).route.handle(New())(context.Background(), rctx)
+
+	if status := rctx.Response.StatusCode(); status != http.StatusBadRequest {
+		t.Errorf("status of a request without an upgrade = %d, want 400", status)
+	}
+}